@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"nstu/internal/api/handler"
 	"nstu/internal/api/router"
 	"nstu/internal/api/server"
@@ -9,10 +11,13 @@ import (
 	"nstu/internal/service"
 	"nstu/internal/tg"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	cnfModel "nstu/internal/config"
 	cnfLoad "nstu/pkg/config"
+	tgpkg "nstu/pkg/tg"
 )
 
 func init() {
@@ -38,7 +43,7 @@ func main() {
 	apiConf := &cnfModel.Api{}
 
 	// Загрузка конфигурации с путем к .env
-	err := cnfLoad.Load(envPath)
+	err := cnfLoad.Load(envPath, dbConf, tgConf, apiConf)
 	if err != nil {
 		logger.Log.Fatal().Err(err).Msg("Ошибка загрузки конфигурации")
 	}
@@ -48,7 +53,6 @@ func main() {
 	if err != nil {
 		logger.Log.Fatal().Err(err).Msg("Ошибка подключения к базе данных")
 	}
-	defer db.Close()
 
 	// Иницилизация структуры для работы с БД
 	repo := postgres.NewRepository(db)
@@ -57,18 +61,64 @@ func main() {
 	srv := service.NewService(repo)
 
 	// Иницилизация бота
-	tg.InitBot(tgConf, srv.GetMessageChan())
+	tg.InitBot(tgConf, srv, db.DB)
 
 	handler := handler.NewHandler(srv)
 
-	router := router.NewRouter(handler, apiConf.LimiterRate, apiConf.LimiterBurst)
+	r := router.NewRouter(handler, tgConf, apiConf.LimiterRate, apiConf.LimiterBurst)
 
-	server := server.NewServer(router, apiConf.URL())
+	// В режиме webhook обновления бота принимает тот же HTTP сервер
+	if tgConf.GetUpdateMode() == string(tgpkg.UpdateModeWebhook) {
+		router.RegisterWebhook(r, tgConf.GetWebhookPath(), tg.Bot.WebhookHandler())
+	}
+
+	server := server.NewServer(r, apiConf.URL())
+
+	// Контекст отменяется при получении сигнала завершения
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Запускаем сервер и бота, первая ошибка любого из них завершает приложение
+	errChan := make(chan error, 2)
+	go func() {
+		if err := server.Start(); err != nil {
+			errChan <- fmt.Errorf("ошибка работы сервера: %w", err)
+		}
+	}()
+	go func() {
+		if err := tg.Run(ctx); err != nil {
+			errChan <- fmt.Errorf("ошибка работы бота: %w", err)
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		logger.Log.Info().Msg("Получен сигнал завершения, останавливаем приложение")
+	case err := <-errChan:
+		logger.Log.Error().Err(err).Msg("Аварийная остановка приложения")
+		exitCode = 1
+	}
+
+	// Останавливаем компоненты по очереди: сначала сервер, чтобы не принимать новые заявки,
+	// затем бота с отправкой оставшихся уведомлений, в конце закрываем БД
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), apiConf.GetShutdownTimeout())
+	defer cancel()
 
-	// Запускаем сервер и ждем сигнала для завершения
-	if err := server.RunUntilSignal(); err != nil {
-		logger.Log.Fatal().Err(err).Msg("Ошибка работы сервера")
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		exitCode = 1
 	}
+	if err := tg.Shutdown(shutdownCtx); err != nil {
+		logger.Log.Error().Err(err).Msg("Ошибка при остановке бота")
+		exitCode = 1
+	}
+	if err := db.Close(); err != nil {
+		logger.Log.Error().Err(err).Msg("Ошибка закрытия соединения с базой данных")
+		exitCode = 1
+	}
+
+	logger.Log.Info().Msg("Приложение остановлено")
+	os.Exit(exitCode)
 }
 
 func generateTestData(repo *postgres.PostgresRepository) error {
@@ -0,0 +1,8 @@
+@echo off
+setlocal
+set "batch_dir=%~dp0"
+cd %batch_dir%
+go run main.go mermaid ../../docs/states.mmd
+go run main.go dot ../../docs/states.dot
+pause
+endlocal
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"nstu/internal/logger"
+	"nstu/internal/tg"
+	"os"
+
+	tgpkg "nstu/pkg/tg"
+)
+
+// Рисует диаграмму состояний бота: go run main.go [dot|mermaid] [путь к файлу].
+// По умолчанию Mermaid в стандартный вывод.
+func main() {
+	format := tgpkg.DiagramMermaid
+	if len(os.Args) > 1 {
+		format = tgpkg.DiagramFormat(os.Args[1])
+	}
+
+	diagram, err := tgpkg.RenderDiagram(tg.States(), format)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Ошибка построения диаграммы состояний")
+	}
+
+	if len(os.Args) < 3 {
+		os.Stdout.WriteString(diagram)
+		return
+	}
+
+	if err := os.WriteFile(os.Args[2], []byte(diagram), 0o644); err != nil {
+		logger.Log.Fatal().Err(err).Str("path", os.Args[2]).Msg("Ошибка записи диаграммы состояний")
+	}
+	logger.Log.Info().Str("path", os.Args[2]).Str("format", string(format)).Msg("Диаграмма состояний записана")
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS form_status_history;
+DROP INDEX IF EXISTS idx_forms_status;
+ALTER TABLE forms DROP COLUMN IF EXISTS status;
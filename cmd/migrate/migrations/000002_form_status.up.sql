@@ -0,0 +1,18 @@
+-- Добавляем статус заявки
+ALTER TABLE forms
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'new'
+    CHECK (status IN ('new', 'in_progress', 'waiting_for_user', 'resolved', 'rejected', 'withdrawn'));
+
+CREATE INDEX idx_forms_status ON forms(status);
+
+-- История изменения статусов заявок
+CREATE TABLE form_status_history (
+    id BIGSERIAL PRIMARY KEY,                                  -- Уникальный ID записи
+    form_id BIGINT NOT NULL REFERENCES forms(id) ON DELETE CASCADE, -- ID заявки
+    from_status VARCHAR(32) NOT NULL,                          -- Предыдущий статус
+    to_status VARCHAR(32) NOT NULL,                            -- Новый статус
+    changed_by BIGINT NOT NULL,                                -- ID пользователя Telegram, изменившего статус
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_form_status_history_form_id ON form_status_history(form_id);
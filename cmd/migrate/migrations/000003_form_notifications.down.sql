@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS form_notifications;
+ALTER TABLE forms DROP COLUMN IF EXISTS assignee_id;
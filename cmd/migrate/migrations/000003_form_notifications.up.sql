@@ -0,0 +1,13 @@
+-- Оператор, взявший заявку в работу
+ALTER TABLE forms ADD COLUMN assignee_id BIGINT REFERENCES users(id);
+
+-- Уведомления о заявках, отправленные в чаты администраторов
+CREATE TABLE form_notifications (
+    chat_id BIGINT NOT NULL,                                        -- ID чата, в который отправлено уведомление
+    message_id BIGINT NOT NULL,                                     -- ID сообщения в чате
+    form_id BIGINT NOT NULL REFERENCES forms(id) ON DELETE CASCADE, -- ID заявки
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, message_id)
+);
+
+CREATE INDEX idx_form_notifications_form_id ON form_notifications(form_id);
@@ -0,0 +1,17 @@
+-- Переписка оператора и пользователя по заявке
+CREATE TABLE form_messages (
+    id BIGSERIAL PRIMARY KEY,                                       -- Уникальный ID сообщения
+    form_id BIGINT NOT NULL REFERENCES forms(id) ON DELETE CASCADE, -- ID заявки
+    author_id BIGINT NOT NULL,                                      -- ID автора сообщения в Telegram
+    direction VARCHAR(16) NOT NULL CHECK (direction IN ('operator', 'user')), -- Кто написал сообщение
+    text VARCHAR(4096) NOT NULL,                                    -- Текст сообщения
+    admin_chat_id BIGINT NOT NULL,                                  -- Чат администраторов
+    admin_message_id BIGINT NOT NULL,                               -- Сообщение в чате администраторов
+    user_chat_id BIGINT NOT NULL,                                   -- Личный чат пользователя с ботом
+    user_message_id BIGINT NOT NULL,                                -- Сообщение в личном чате пользователя
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_form_messages_form_id ON form_messages(form_id);
+CREATE UNIQUE INDEX idx_form_messages_admin ON form_messages(admin_chat_id, admin_message_id);
+CREATE UNIQUE INDEX idx_form_messages_user ON form_messages(user_chat_id, user_message_id);
@@ -0,0 +1,8 @@
+-- Состояния пользователей бота при TG_STATE_STORE=postgres
+CREATE TABLE bot_states (
+    user_id BIGINT PRIMARY KEY,              -- ID пользователя в Telegram
+    state VARCHAR(255) NOT NULL,             -- Название состояния
+    expires_at TIMESTAMP WITH TIME ZONE      -- Время истечения, NULL - бессрочно
+);
+
+CREATE INDEX idx_bot_states_expires_at ON bot_states(expires_at);
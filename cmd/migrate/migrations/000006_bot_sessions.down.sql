@@ -0,0 +1 @@
+ALTER TABLE bot_states DROP COLUMN IF EXISTS data;
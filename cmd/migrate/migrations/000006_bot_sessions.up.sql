@@ -0,0 +1,2 @@
+-- Данные сессии пользователя хранятся вместе с состоянием и истекают вместе с ним
+ALTER TABLE bot_states ADD COLUMN data JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_forms_source;
+ALTER TABLE forms DROP COLUMN IF EXISTS source;
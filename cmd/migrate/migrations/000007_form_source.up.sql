@@ -0,0 +1,4 @@
+-- Источник трафика заявки из ссылки t.me/bot?start=src_<campaign>, пустой - неизвестен
+ALTER TABLE forms ADD COLUMN source VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_forms_source ON forms(source);
@@ -0,0 +1,165 @@
+// Package apierror содержит типизированные ошибки API и единый формат
+// JSON ответа с ошибкой.
+//
+// Формат ответа:
+//
+//	{
+//	  "status": "error",
+//	  "code": "validation_failed",
+//	  "message": "Request validation failed",
+//	  "details": [{"field": "name", "rule": "required", "message": "Field is required"}]
+//	}
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind тип ошибки API, определяющий HTTP статус ответа
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindUnauthorized
+	KindNotFound
+	KindConflict
+	KindRateLimited
+	KindMethodNotAllowed
+)
+
+// Коды ошибок по умолчанию для каждого типа
+const (
+	CodeInternal     = "internal"
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeRateLimited  = "rate_limited"
+	CodeMethod       = "method_not_allowed"
+)
+
+// Status возвращает HTTP статус для типа ошибки
+func (k Kind) Status() int {
+	switch k {
+	case KindBadRequest, KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError описание ошибки валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`           // Имя поля в JSON
+	Rule    string `json:"rule"`            // Нарушенное правило (required, max, ...)
+	Param   string `json:"param,omitempty"` // Параметр правила (например, 128 для max)
+	Message string `json:"message"`         // Описание ошибки
+}
+
+// Error типизированная ошибка API
+type Error struct {
+	Kind    Kind
+	Code    string       // Машиночитаемый код ошибки
+	Message string       // Сообщение для клиента
+	Details []FieldError // Ошибки полей для KindValidation
+	Err     error        // Исходная ошибка. Клиенту не отдается
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status возвращает HTTP статус ошибки
+func (e *Error) Status() int {
+	return e.Kind.Status()
+}
+
+// WithCode заменяет код ошибки
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// Wrap сохраняет исходную ошибку
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// New создает ошибку заданного типа
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// BadRequest ошибка некорректного запроса (например, невалидный JSON)
+func BadRequest(message string) *Error {
+	return New(KindBadRequest, CodeBadRequest, message)
+}
+
+// Validation ошибка валидации с описанием полей
+func Validation(details ...FieldError) *Error {
+	e := New(KindValidation, CodeValidation, "Request validation failed")
+	e.Details = details
+	return e
+}
+
+// Unauthorized ошибка авторизации
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, CodeUnauthorized, message)
+}
+
+// NotFound ошибка отсутствия ресурса
+func NotFound(message string) *Error {
+	return New(KindNotFound, CodeNotFound, message)
+}
+
+// Conflict ошибка конфликта состояния ресурса
+func Conflict(message string) *Error {
+	return New(KindConflict, CodeConflict, message)
+}
+
+// RateLimited ошибка превышения лимита запросов
+func RateLimited(message string) *Error {
+	return New(KindRateLimited, CodeRateLimited, message)
+}
+
+// Internal внутренняя ошибка сервера. Исходная ошибка клиенту не отдается.
+func Internal(err error) *Error {
+	return New(KindInternal, CodeInternal, "Internal server error").Wrap(err)
+}
+
+// From приводит произвольную ошибку к *Error.
+// Ошибки, не являющиеся *Error, считаются внутренними.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal(err)
+}
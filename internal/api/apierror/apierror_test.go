@@ -0,0 +1,98 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestKindStatus(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want int
+	}{
+		{kind: KindInternal, want: http.StatusInternalServerError},
+		{kind: KindBadRequest, want: http.StatusBadRequest},
+		{kind: KindValidation, want: http.StatusBadRequest},
+		{kind: KindUnauthorized, want: http.StatusUnauthorized},
+		{kind: KindNotFound, want: http.StatusNotFound},
+		{kind: KindConflict, want: http.StatusConflict},
+		{kind: KindRateLimited, want: http.StatusTooManyRequests},
+		{kind: KindMethodNotAllowed, want: http.StatusMethodNotAllowed},
+		{kind: Kind(100), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.kind), func(t *testing.T) {
+			if got := tt.kind.Status(); got != tt.want {
+				t.Errorf("Status() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		kind Kind
+		code string
+	}{
+		{name: "bad request", err: BadRequest("Invalid JSON"), kind: KindBadRequest, code: CodeBadRequest},
+		{name: "validation", err: Validation(), kind: KindValidation, code: CodeValidation},
+		{name: "unauthorized", err: Unauthorized("Missing initData"), kind: KindUnauthorized, code: CodeUnauthorized},
+		{name: "not found", err: NotFound("Form not found"), kind: KindNotFound, code: CodeNotFound},
+		{name: "conflict", err: Conflict("Form is closed"), kind: KindConflict, code: CodeConflict},
+		{name: "rate limited", err: RateLimited("Too many requests"), kind: KindRateLimited, code: CodeRateLimited},
+		{name: "internal", err: Internal(errors.New("db down")), kind: KindInternal, code: CodeInternal},
+		{name: "custom code", err: Conflict("Form is closed").WithCode("form_closed"), kind: KindConflict, code: "form_closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Kind != tt.kind || tt.err.Code != tt.code {
+				t.Errorf("kind %d code %q, want %d %q", tt.err.Kind, tt.err.Code, tt.kind, tt.code)
+			}
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	notFound := NotFound("Form not found")
+	cause := errors.New("db down")
+
+	tests := []struct {
+		name string
+		err  error
+		want *Error // nil - ожидается внутренняя ошибка с исходной err
+	}{
+		{name: "api error", err: notFound, want: notFound},
+		{name: "wrapped api error", err: fmt.Errorf("get form: %w", notFound), want: notFound},
+		{name: "plain error", err: cause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if tt.want != nil {
+				if got != tt.want {
+					t.Errorf("From() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if got.Kind != KindInternal || !errors.Is(got, tt.err) {
+				t.Errorf("From() = %v, want internal error wrapping %v", got, tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	if got := NotFound("Form not found").Error(); got != "not_found: Form not found" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := Internal(errors.New("db down")).Error(); got != "internal: Internal server error: db down" {
+		t.Errorf("Error() = %q", got)
+	}
+}
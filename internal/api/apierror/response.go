@@ -0,0 +1,50 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"nstu/internal/logger"
+)
+
+// Response тело ответа с ошибкой
+type Response struct {
+	Status  string       `json:"status"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// Write отправляет ошибку клиенту в едином формате.
+// Внутренние ошибки дополнительно логируются.
+func Write(w http.ResponseWriter, err error) {
+	apiErr := From(err)
+
+	if apiErr.Kind == KindInternal {
+		logger.Log.Error().Err(apiErr.Err).Msg("Внутренняя ошибка API")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status())
+	if err := json.NewEncoder(w).Encode(Response{
+		Status:  "error",
+		Code:    apiErr.Code,
+		Message: apiErr.Message,
+		Details: apiErr.Details,
+	}); err != nil {
+		logger.Log.Error().Err(err).Msg("Ошибка записи ответа")
+	}
+}
+
+// NotFoundHandler отвечает ошибкой not_found на неизвестные маршруты
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, NotFound("Route not found"))
+	})
+}
+
+// MethodNotAllowedHandler отвечает ошибкой на неподдерживаемый метод
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, New(KindMethodNotAllowed, CodeMethod, "Method not allowed"))
+	})
+}
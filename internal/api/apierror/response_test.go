@@ -0,0 +1,94 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	details := []FieldError{{Field: "name", Rule: "required", Message: "Field is required"}}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   Response
+	}{
+		{
+			name:   "not found",
+			err:    NotFound("Form not found"),
+			status: http.StatusNotFound,
+			want:   Response{Status: "error", Code: CodeNotFound, Message: "Form not found"},
+		},
+		{
+			name:   "validation",
+			err:    Validation(details...),
+			status: http.StatusBadRequest,
+			want:   Response{Status: "error", Code: CodeValidation, Message: "Request validation failed", Details: details},
+		},
+		{
+			// Текст исходной ошибки клиенту не отдается
+			name:   "internal",
+			err:    errors.New("pq: password authentication failed"),
+			status: http.StatusInternalServerError,
+			want:   Response{Status: "error", Code: CodeInternal, Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Write(rec, tt.err)
+			assertResponse(t, rec, tt.status, tt.want)
+		})
+	}
+}
+
+func TestFallbackHandlers(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+		status  int
+		code    string
+	}{
+		{name: "not found", handler: NotFoundHandler(), status: http.StatusNotFound, code: CodeNotFound},
+		{name: "method not allowed", handler: MethodNotAllowedHandler(), status: http.StatusMethodNotAllowed, code: CodeMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil))
+
+			var resp Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.status || resp.Code != tt.code {
+				t.Errorf("response %d %q, want %d %q", rec.Code, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// assertResponse проверяет статус, заголовок и тело ответа с ошибкой
+func assertResponse(t *testing.T, rec *httptest.ResponseRecorder, status int, want Response) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status = %d, want %d", rec.Code, status)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var got Response
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+	"nstu/internal/api/apierror"
+	"nstu/internal/api/middleware"
+	"nstu/internal/api/request"
+	"nstu/internal/api/response"
+	"nstu/internal/api/validator"
+)
+
+// HandleNewForm создает новую заявку от пользователя
+func (h *Handler) HandleNewForm(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		apierror.Write(w, apierror.Unauthorized("User is not authorized"))
+		return
+	}
+
+	var req request.FormRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, err)
+		return
+	}
+	req.Normalize()
+
+	if err := validator.Validate(&req); err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	form := req.ToModel()
+	if err := h.service.CreateForm(user, form); err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, response.NewFormCreatedResponse(form))
+}
+
+// HandleListForms возвращает заявки текущего пользователя
+func (h *Handler) HandleListForms(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		apierror.Write(w, apierror.Unauthorized("User is not authorized"))
+		return
+	}
+
+	offset, limit, err := pagination(r)
+	if err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	forms, err := h.service.ListUserForms(user.ID, offset, limit)
+	if err != nil {
+		apierror.Write(w, serviceError(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response.NewFormListResponse(forms, offset, limit))
+}
+
+// HandleGetForm возвращает заявку текущего пользователя по ID
+func (h *Handler) HandleGetForm(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		apierror.Write(w, apierror.Unauthorized("User is not authorized"))
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	form, err := h.service.GetUserForm(user.ID, id)
+	if err != nil {
+		apierror.Write(w, serviceError(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response.NewFormResponse(form))
+}
+
+// HandleUpdateForm изменяет заявку текущего пользователя
+func (h *Handler) HandleUpdateForm(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		apierror.Write(w, apierror.Unauthorized("User is not authorized"))
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	var req request.FormRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, err)
+		return
+	}
+	req.Normalize()
+
+	if err := validator.Validate(&req); err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	form := req.ToModel()
+	form.ID.ID = id
+	if err := h.service.UpdateUserForm(user.ID, form); err != nil {
+		apierror.Write(w, serviceError(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response.NewFormResponse(form))
+}
+
+// HandleWithdrawForm отзывает заявку текущего пользователя
+func (h *Handler) HandleWithdrawForm(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		apierror.Write(w, apierror.Unauthorized("User is not authorized"))
+		return
+	}
+
+	id, err := pathID(r)
+	if err != nil {
+		apierror.Write(w, err)
+		return
+	}
+
+	if err := h.service.WithdrawUserForm(user.ID, id); err != nil {
+		apierror.Write(w, serviceError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
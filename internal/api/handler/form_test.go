@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"nstu/internal/api/apierror"
+	"nstu/internal/api/middleware"
+	"nstu/internal/api/response"
+	"nstu/internal/model"
+	"nstu/internal/service"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+const testToken = "123456:test-token"
+
+type testAuthConfig struct{}
+
+func (testAuthConfig) GetToken() string             { return testToken }
+func (testAuthConfig) GetAuthMaxAge() time.Duration { return time.Hour }
+
+// stubService сервис для тестов обработчиков: заявки пользователя 42 и ошибка err для всех вызовов.
+// Методы, которые обработчики не вызывают, паникуют через встроенный nil service.Servicer.
+type stubService struct {
+	service.Servicer
+
+	forms []model.Form
+	err   error
+
+	userID        int64 // Пользователь из последнего вызова
+	offset, limit int
+	updated       *model.Form
+	withdrawn     int64
+}
+
+func (s *stubService) CreateForm(user *model.User, form *model.Form) error {
+	s.userID = user.ID
+	if s.err != nil {
+		return s.err
+	}
+	form.ID.ID = 7
+	form.UpdatedAt.UpdatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return nil
+}
+
+func (s *stubService) ListUserForms(userID int64, offset, limit int) ([]model.Form, error) {
+	s.userID, s.offset, s.limit = userID, offset, limit
+	return s.forms, s.err
+}
+
+func (s *stubService) GetUserForm(userID, formID int64) (*model.Form, error) {
+	s.userID = userID
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, form := range s.forms {
+		if form.ID.ID == formID {
+			return &form, nil
+		}
+	}
+	return nil, service.ErrFormNotFound
+}
+
+func (s *stubService) UpdateUserForm(userID int64, form *model.Form) error {
+	s.userID, s.updated = userID, form
+	if s.err != nil {
+		return s.err
+	}
+	form.Status = model.FormStatusNew
+	return nil
+}
+
+func (s *stubService) WithdrawUserForm(userID, formID int64) error {
+	s.userID, s.withdrawn = userID, formID
+	return s.err
+}
+
+// newTestRouter собирает маршруты API с проверкой initData, как в router.NewRouter
+func newTestRouter(srv service.Servicer) http.Handler {
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.AuthMiddleware(testAuthConfig{}))
+	NewHandler(srv).RegisterRoutes(api)
+	return r
+}
+
+// newRequest создает запрос от пользователя userID с подписанным initData.
+// Для userID 0 заголовок авторизации не добавляется.
+func newRequest(method, target, body string, userID int64) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userID == 0 {
+		return req
+	}
+
+	authDate := time.Now()
+	payload := map[string]string{
+		"query_id": "AAH",
+		"user":     fmt.Sprintf(`{"id":%d,"first_name":"Ivan"}`, userID),
+	}
+	values := url.Values{}
+	for k, v := range payload {
+		values.Set(k, v)
+	}
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", initdata.Sign(payload, testToken, authDate))
+	req.Header.Set("Authorization", "tma "+values.Encode())
+	return req
+}
+
+// userForm заявка пользователя 42
+func userForm(id int64, status model.FormStatus) model.Form {
+	form := model.Form{UserID: 42, Name: "Ivan", Status: status}
+	form.ID.ID = id
+	return form
+}
+
+func TestFormEndpointsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		userID int64
+		err    error
+		status int
+		code   string
+	}{
+		{name: "create unauthorized", method: http.MethodPost, target: "/api/v1/form", body: `{"name":"Ivan"}`, status: http.StatusUnauthorized, code: middleware.CodeAuthMissing},
+		{name: "create invalid json", method: http.MethodPost, target: "/api/v1/form", body: `{"name":`, userID: 42, status: http.StatusBadRequest, code: apierror.CodeBadRequest},
+		{name: "create blank name", method: http.MethodPost, target: "/api/v1/form", body: `{"name":"   "}`, userID: 42, status: http.StatusBadRequest, code: apierror.CodeValidation},
+		{name: "list bad offset", method: http.MethodGet, target: "/api/v1/forms?offset=-1", userID: 42, status: http.StatusBadRequest, code: apierror.CodeValidation},
+		{name: "list bad limit", method: http.MethodGet, target: "/api/v1/forms?limit=101", userID: 42, status: http.StatusBadRequest, code: apierror.CodeValidation},
+		{name: "get zero id", method: http.MethodGet, target: "/api/v1/forms/0", userID: 42, status: http.StatusBadRequest, code: apierror.CodeBadRequest},
+		{name: "get not found", method: http.MethodGet, target: "/api/v1/forms/8", userID: 42, err: service.ErrFormNotFound, status: http.StatusNotFound, code: apierror.CodeNotFound},
+		{name: "update closed", method: http.MethodPut, target: "/api/v1/forms/7", body: `{"name":"Ivan"}`, userID: 42, err: service.ErrFormClosed, status: http.StatusConflict, code: "form_closed"},
+		{name: "withdraw closed", method: http.MethodDelete, target: "/api/v1/forms/7", userID: 42, err: service.ErrFormClosed, status: http.StatusConflict, code: "form_closed"},
+		{name: "withdraw concurrently", method: http.MethodDelete, target: "/api/v1/forms/7", userID: 42, err: service.ErrStatusConflict, status: http.StatusConflict, code: apierror.CodeConflict},
+		{name: "withdraw internal", method: http.MethodDelete, target: "/api/v1/forms/7", userID: 42, err: fmt.Errorf("db down"), status: http.StatusInternalServerError, code: apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &stubService{forms: []model.Form{userForm(7, model.FormStatusNew)}, err: tt.err}
+			rec := httptest.NewRecorder()
+			newTestRouter(srv).ServeHTTP(rec, newRequest(tt.method, tt.target, tt.body, tt.userID))
+
+			var resp apierror.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.status || resp.Code != tt.code {
+				t.Errorf("response %d %q, want %d %q", rec.Code, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandleNewForm(t *testing.T) {
+	srv := &stubService{}
+	rec := httptest.NewRecorder()
+	newTestRouter(srv).ServeHTTP(rec, newRequest(http.MethodPost, "/api/v1/form", `{"name":" Ivan ","comment":"Вопрос"}`, 42))
+
+	var resp response.FormCreatedResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if rec.Code != http.StatusCreated || resp.ID != 7 || srv.userID != 42 {
+		t.Errorf("response %d %+v for user %d", rec.Code, resp, srv.userID)
+	}
+}
+
+func TestHandleListForms(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		offset, limit int
+	}{
+		{name: "defaults", query: "", offset: 0, limit: defaultLimit},
+		{name: "page", query: "?offset=20&limit=10", offset: 20, limit: 10},
+		{name: "max limit", query: "?limit=100", offset: 0, limit: maxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &stubService{forms: []model.Form{userForm(7, model.FormStatusNew), userForm(5, model.FormStatusResolved)}}
+			rec := httptest.NewRecorder()
+			newTestRouter(srv).ServeHTTP(rec, newRequest(http.MethodGet, "/api/v1/forms"+tt.query, "", 42))
+
+			var resp response.FormListResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != http.StatusOK || len(resp.Items) != 2 || resp.Items[1].Status != model.FormStatusResolved {
+				t.Errorf("response %d %+v", rec.Code, resp)
+			}
+			if srv.userID != 42 || srv.offset != tt.offset || srv.limit != tt.limit || resp.Offset != tt.offset || resp.Limit != tt.limit {
+				t.Errorf("listed user %d offset %d limit %d, want 42 %d %d", srv.userID, srv.offset, srv.limit, tt.offset, tt.limit)
+			}
+		})
+	}
+}
+
+func TestHandleGetForm(t *testing.T) {
+	srv := &stubService{forms: []model.Form{userForm(7, model.FormStatusInProgress)}}
+	rec := httptest.NewRecorder()
+	newTestRouter(srv).ServeHTTP(rec, newRequest(http.MethodGet, "/api/v1/forms/7", "", 42))
+
+	var resp response.FormResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if rec.Code != http.StatusOK || resp.ID != 7 || resp.Status != model.FormStatusInProgress {
+		t.Errorf("response %d %+v", rec.Code, resp)
+	}
+}
+
+func TestHandleUpdateForm(t *testing.T) {
+	srv := &stubService{}
+	rec := httptest.NewRecorder()
+	newTestRouter(srv).ServeHTTP(rec, newRequest(http.MethodPut, "/api/v1/forms/7", `{"name":"Ivan","feedback":" @ivan "}`, 42))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if srv.userID != 42 || srv.updated == nil || srv.updated.ID.ID != 7 || srv.updated.Feedback != "@ivan" {
+		t.Errorf("updated form %+v for user %d", srv.updated, srv.userID)
+	}
+}
+
+func TestHandleWithdrawForm(t *testing.T) {
+	srv := &stubService{}
+	rec := httptest.NewRecorder()
+	newTestRouter(srv).ServeHTTP(rec, newRequest(http.MethodDelete, "/api/v1/forms/7", "", 42))
+
+	if rec.Code != http.StatusNoContent || srv.withdrawn != 7 || srv.userID != 42 {
+		t.Errorf("response %d, withdrawn %d by %d", rec.Code, srv.withdrawn, srv.userID)
+	}
+}
@@ -1,12 +1,26 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"nstu/internal/api/apierror"
+	"nstu/internal/logger"
 	"nstu/internal/service"
+	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
+// maxBodySize максимальный размер тела запроса
+const maxBodySize = 1 << 20
+
+// Параметры пагинации по умолчанию
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
 type Handler struct {
 	service service.Servicer
 }
@@ -19,12 +33,84 @@ func NewHandler(srv service.Servicer) *Handler {
 
 // RegisterRoutes регистрирует все маршруты приложения
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	// Регистрируем маршруты
-	router.HandleFunc("/form", h.HandleNewForm).Methods(http.MethodPost)
+	// Регистрируем маршруты. OPTIONS нужен, чтобы preflight запрос браузера дошел
+	// до CORS middleware: без совпавшего маршрута mux отвечает 405 без заголовков CORS
+	router.HandleFunc("/form", h.HandleNewForm).Methods(http.MethodPost, http.MethodOptions)
+	router.HandleFunc("/forms", h.HandleListForms).Methods(http.MethodGet, http.MethodOptions)
+	router.HandleFunc("/forms/{id:[0-9]+}", h.HandleGetForm).Methods(http.MethodGet, http.MethodOptions)
+	router.HandleFunc("/forms/{id:[0-9]+}", h.HandleUpdateForm).Methods(http.MethodPut)
+	router.HandleFunc("/forms/{id:[0-9]+}", h.HandleWithdrawForm).Methods(http.MethodDelete)
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Log.Error().Err(err).Msg("Ошибка записи ответа")
+	}
 }
 
-// HandleNewForm создает новую заявку от пользователя
-func (h *Handler) HandleNewForm(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("form processed"))
+// decodeJSON читает тело запроса в dst
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(dst); err != nil {
+		return apierror.BadRequest("Invalid JSON body").Wrap(err)
+	}
+	return nil
+}
+
+// pathID возвращает ID из пути запроса
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, apierror.BadRequest("Invalid id")
+	}
+	return id, nil
+}
+
+// pagination возвращает offset и limit из query параметров
+func pagination(r *http.Request) (offset int, limit int, err error) {
+	query := r.URL.Query()
+	limit = defaultLimit
+
+	if raw := query.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, apierror.Validation(apierror.FieldError{
+				Field:   "offset",
+				Rule:    "min",
+				Param:   "0",
+				Message: "Must be a non-negative integer",
+			})
+		}
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, apierror.Validation(apierror.FieldError{
+				Field:   "limit",
+				Rule:    "max",
+				Param:   strconv.Itoa(maxLimit),
+				Message: "Must be an integer between 1 and " + strconv.Itoa(maxLimit),
+			})
+		}
+	}
+
+	return offset, limit, nil
+}
+
+// serviceError приводит ошибки бизнес-логики к ошибкам API
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrFormNotFound):
+		return apierror.NotFound("Form not found")
+	case errors.Is(err, service.ErrFormClosed):
+		return apierror.Conflict("Form is already closed").WithCode("form_closed")
+	case errors.Is(err, service.ErrInvalidTransition):
+		return apierror.Conflict("Form status transition is not allowed").WithCode("invalid_transition")
+	case errors.Is(err, service.ErrStatusConflict):
+		return apierror.Conflict("Form status was changed concurrently")
+	default:
+		return err
+	}
 }
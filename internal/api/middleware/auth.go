@@ -2,9 +2,16 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"nstu/internal/api/apierror"
+	"nstu/internal/logger"
 	"nstu/internal/model"
+	"strings"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
 )
 
 // Создаем тип ключа для контекста
@@ -14,57 +21,106 @@ const (
 	userContextKey contextKey = "user"
 )
 
-// AuthMiddleware проверяет наличие токена в заголовке и возвращает ошибку, если токен не найден
-func AuthMiddleware() func(http.Handler) http.Handler {
+// authScheme префикс заголовка Authorization для initData Telegram Mini App
+const authScheme = "tma"
+
+// AuthConfig интерфейс для конфигурации проверки initData
+type AuthConfig interface {
+	GetToken() string
+	GetAuthMaxAge() time.Duration
+}
+
+// Коды ошибок авторизации
+const (
+	CodeAuthMissing          = "auth_missing"
+	CodeAuthMalformed        = "auth_malformed"
+	CodeAuthExpired          = "auth_expired"
+	CodeAuthInvalidSignature = "auth_invalid_signature"
+)
+
+// AuthMiddleware проверяет initData Telegram Mini App из заголовка Authorization
+// ("tma <initData>"), валидирует подпись токеном бота и срок жизни auth_date,
+// после чего кладет пользователя в контекст запроса.
+func AuthMiddleware(cfg AuthConfig) func(http.Handler) http.Handler {
+	token := cfg.GetToken()
+	maxAge := cfg.GetAuthMaxAge()
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// token := r.Header.Get("Authorization")
-			// if token == "" {
-			// 	logger.Log.Error().
-			// 		Msg("Empty token")
-			// 	w.WriteHeader(http.StatusUnauthorized)
-			// 	json.NewEncoder(w).Encode(u.NewResponse("error", "Empty token"))
-			// 	return
-			// }
-
-			// // Разбиваем токен на части
-			// parts := strings.SplitN(token, " ", 2)
-			// if len(parts) != 2 || parts[0] != "tma" {
-			// 	logger.Log.Error().
-			// 		Msg("Неверный формат токена")
-			// 	w.WriteHeader(http.StatusUnauthorized)
-			// 	json.NewEncoder(w).Encode(u.NewResponse("error", "Invalid token format"))
-			// 	return
-			// }
-
-			// user, err := service.AuthInitData(parts[1])
-			// if err != nil {
-			// 	logger.Log.Error().
-			// 		Err(err).
-			// 		Msg("Ошибка аутентификации")
-			// 	w.WriteHeader(http.StatusUnauthorized)
-			// 	json.NewEncoder(w).Encode(u.NewResponse("error", err.Error()))
-			// 	return
-			// }
-
-			///////////////////////////////////////////////////////////////////////////////
-			// user, err := service.AuthInitData("")
-			// if err != nil {
-			// 	logger.Log.Error().
-			// 		Err(err).
-			// 		Msg("Ошибка аутентификации")
-			// 	w.WriteHeader(http.StatusUnauthorized)
-			// 	return
-			// }
-			///////////////////////////////////////////////////////////////////////////////
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				writeAuthError(w, CodeAuthMissing, "Authorization header is empty")
+				return
+			}
+
+			// Разбиваем заголовок на схему и initData
+			parts := strings.SplitN(header, " ", 2)
+			if len(parts) != 2 || parts[0] != authScheme || strings.TrimSpace(parts[1]) == "" {
+				writeAuthError(w, CodeAuthMalformed, "Authorization header must be in format \"tma <initData>\"")
+				return
+			}
+			rawInitData := strings.TrimSpace(parts[1])
+
+			if err := initdata.Validate(rawInitData, token, maxAge); err != nil {
+				code, message := classifyInitDataError(err)
+				logger.Log.Error().
+					Err(err).
+					Str("code", code).
+					Str("remote_addr", r.RemoteAddr).
+					Msg("Ошибка проверки initData")
+				writeAuthError(w, code, message)
+				return
+			}
+
+			data, err := initdata.Parse(rawInitData)
+			if err != nil {
+				logger.Log.Error().Err(err).Msg("Ошибка разбора initData")
+				writeAuthError(w, CodeAuthMalformed, "Init data has unexpected format")
+				return
+			}
+			if data.User.ID == 0 {
+				writeAuthError(w, CodeAuthMalformed, "Init data does not contain user")
+				return
+			}
 
 			// Добавляем пользователя в контекст
-			ctx := context.WithValue(r.Context(), userContextKey, "user")
+			ctx := context.WithValue(r.Context(), userContextKey, userFromInitData(data.User))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// classifyInitDataError сопоставляет ошибку библиотеки initdata с кодом ответа
+func classifyInitDataError(err error) (code string, message string) {
+	switch {
+	case errors.Is(err, initdata.ErrExpired):
+		return CodeAuthExpired, "Init data is expired"
+	case errors.Is(err, initdata.ErrSignInvalid):
+		return CodeAuthInvalidSignature, "Init data signature is invalid"
+	case errors.Is(err, initdata.ErrSignMissing):
+		return CodeAuthMalformed, "Init data signature is missing"
+	case errors.Is(err, initdata.ErrAuthDateMissing):
+		return CodeAuthMalformed, "Init data auth_date is missing"
+	default:
+		return CodeAuthMalformed, "Init data has unexpected format"
+	}
+}
+
+// userFromInitData преобразует пользователя Telegram из initData в модель
+func userFromInitData(u initdata.User) *model.User {
+	return &model.User{
+		ID:        u.ID,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		UserName:  u.Username,
+	}
+}
+
+// writeAuthError отправляет ответ 401 с описанием ошибки авторизации
+func writeAuthError(w http.ResponseWriter, code, message string) {
+	apierror.Write(w, apierror.Unauthorized(message).WithCode(code))
+}
+
 // GetUserFromContext получает пользователя из контекста
 func GetUserFromContext(ctx context.Context) (*model.User, error) {
 	user, ok := ctx.Value(userContextKey).(*model.User)
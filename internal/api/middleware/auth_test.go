@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"nstu/internal/api/apierror"
+	"strconv"
+	"testing"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+const testToken = "123456:test-token"
+
+type testAuthConfig struct {
+	maxAge time.Duration
+}
+
+func (c testAuthConfig) GetToken() string             { return testToken }
+func (c testAuthConfig) GetAuthMaxAge() time.Duration { return c.maxAge }
+
+// signInitData подписывает initData токеном так же, как это делает Telegram
+func signInitData(token string, authDate time.Time, user string) string {
+	payload := map[string]string{"query_id": "AAH"}
+	if user != "" {
+		payload["user"] = user
+	}
+
+	values := url.Values{}
+	for k, v := range payload {
+		values.Set(k, v)
+	}
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", initdata.Sign(payload, token, authDate))
+	return values.Encode()
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	const user = `{"id":42,"first_name":"Ivan","last_name":"Petrov","username":"ivan"}`
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		header string
+		maxAge time.Duration
+		status int
+		code   string
+	}{
+		{name: "missing header", header: "", status: http.StatusUnauthorized, code: CodeAuthMissing},
+		{name: "wrong scheme", header: "Bearer " + signInitData(testToken, now, user), status: http.StatusUnauthorized, code: CodeAuthMalformed},
+		{name: "empty init data", header: "tma   ", status: http.StatusUnauthorized, code: CodeAuthMalformed},
+		{name: "invalid signature", header: "tma " + signInitData("654321:other-token", now, user), status: http.StatusUnauthorized, code: CodeAuthInvalidSignature},
+		{name: "missing signature", header: "tma auth_date=1&user=%7B%7D", status: http.StatusUnauthorized, code: CodeAuthMalformed},
+		{name: "expired", header: "tma " + signInitData(testToken, now.Add(-2*time.Hour), user), maxAge: time.Hour, status: http.StatusUnauthorized, code: CodeAuthExpired},
+		{name: "no max age", header: "tma " + signInitData(testToken, now.Add(-48*time.Hour), user), status: http.StatusOK},
+		{name: "without user", header: "tma " + signInitData(testToken, now, ""), status: http.StatusUnauthorized, code: CodeAuthMalformed},
+		{name: "valid", header: "tma " + signInitData(testToken, now, user), maxAge: time.Hour, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				u, err := GetUserFromContext(r.Context())
+				if err != nil {
+					t.Fatalf("user is not in context: %v", err)
+				}
+				if u.ID != 42 || u.FirstName != "Ivan" || u.LastName != "Petrov" || u.UserName != "ivan" {
+					t.Errorf("unexpected user %+v", u)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/forms", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			AuthMiddleware(testAuthConfig{maxAge: tt.maxAge})(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.code == "" {
+				return
+			}
+			var resp apierror.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Code != tt.code {
+				t.Errorf("code = %q, want %q", resp.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestGetUserFromContextEmpty(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := GetUserFromContext(req.Context()); err == nil {
+		t.Error("expected error for request without user")
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"nstu/internal/api/apierror"
 	"sync"
 
 	"golang.org/x/time/rate"
@@ -63,7 +64,7 @@ func RateLimitMiddleware(r rate.Limit, b int) func(http.Handler) http.Handler {
 			l := limiter.GetLimiter(r.RemoteAddr)
 			// если лимит превышен, возвращаем ошибку 429 Too Many Requests
 			if !l.Allow() {
-				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				apierror.Write(w, apierror.RateLimited("Too many requests"))
 				return
 			}
 			// если лимит не превышен, передаем запрос следующему обработчику
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := RateLimitMiddleware(rate.Limit(1.0/3600), 2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		addr   string
+		status int
+	}{
+		{name: "first", addr: "10.0.0.1:1000", status: http.StatusNoContent},
+		{name: "burst", addr: "10.0.0.1:1000", status: http.StatusNoContent},
+		{name: "over limit", addr: "10.0.0.1:1000", status: http.StatusTooManyRequests},
+		{name: "other client", addr: "10.0.0.2:1000", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/forms", nil)
+			req.RemoteAddr = tt.addr
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
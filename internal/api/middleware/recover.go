@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"nstu/internal/api/apierror"
 	"nstu/internal/logger"
 )
 
@@ -12,7 +14,7 @@ func RecoverMiddleware() func(http.Handler) http.Handler {
 			defer func() {
 				if err := recover(); err != nil {
 					logger.Log.Error().Interface("error", err).Msg("Паника в HTTP обработчике")
-					w.WriteHeader(http.StatusInternalServerError)
+					apierror.Write(w, apierror.Internal(fmt.Errorf("panic: %v", err)))
 				}
 			}()
 			next.ServeHTTP(w, r)
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"nstu/internal/api/apierror"
+	"testing"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		code    string
+	}{
+		{
+			name:    "panic",
+			handler: func(w http.ResponseWriter, r *http.Request) { panic("nil map") },
+			status:  http.StatusInternalServerError,
+			code:    apierror.CodeInternal,
+		},
+		{
+			name:    "no panic",
+			handler: func(w http.ResponseWriter, r *http.Request) { apierror.Write(w, apierror.NotFound("Form not found")) },
+			status:  http.StatusNotFound,
+			code:    apierror.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RecoverMiddleware()(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/forms", nil))
+
+			var resp apierror.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.status || resp.Code != tt.code {
+				t.Errorf("response %d %q, want %d %q", rec.Code, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
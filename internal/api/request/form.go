@@ -1,7 +1,28 @@
 package request
 
+import (
+	"nstu/internal/model"
+	"strings"
+)
+
 type FormRequest struct {
 	Name     string `json:"name" validate:"required,max=128"`
 	Feedback string `json:"feedback" validate:"max=256"`
 	Comment  string `json:"comment" validate:"max=512"`
 }
+
+// Normalize убирает пробелы по краям полей заявки
+func (r *FormRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Feedback = strings.TrimSpace(r.Feedback)
+	r.Comment = strings.TrimSpace(r.Comment)
+}
+
+// ToModel преобразует запрос в модель заявки
+func (r *FormRequest) ToModel() *model.Form {
+	return &model.Form{
+		Name:     r.Name,
+		Feedback: r.Feedback,
+		Comment:  r.Comment,
+	}
+}
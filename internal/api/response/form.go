@@ -0,0 +1,62 @@
+package response
+
+import (
+	"nstu/internal/model"
+	"time"
+)
+
+// FormCreatedResponse ответ на создание заявки
+type FormCreatedResponse struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewFormCreatedResponse создает ответ по сохраненной заявке
+func NewFormCreatedResponse(form *model.Form) FormCreatedResponse {
+	return FormCreatedResponse{
+		ID:        form.ID.ID,
+		CreatedAt: form.UpdatedAt.UpdatedAt,
+	}
+}
+
+// FormResponse заявка пользователя
+type FormResponse struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	Feedback  string           `json:"feedback"`
+	Comment   string           `json:"comment"`
+	Status    model.FormStatus `json:"status"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// NewFormResponse создает ответ по заявке
+func NewFormResponse(form *model.Form) FormResponse {
+	return FormResponse{
+		ID:        form.ID.ID,
+		Name:      form.Name,
+		Feedback:  form.Feedback,
+		Comment:   form.Comment,
+		Status:    form.Status,
+		UpdatedAt: form.UpdatedAt.UpdatedAt,
+	}
+}
+
+// FormListResponse страница заявок пользователя
+type FormListResponse struct {
+	Items  []FormResponse `json:"items"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+}
+
+// NewFormListResponse создает ответ со списком заявок
+func NewFormListResponse(forms []model.Form, offset, limit int) FormListResponse {
+	items := make([]FormResponse, 0, len(forms))
+	for i := range forms {
+		items = append(items, NewFormResponse(&forms[i]))
+	}
+	return FormListResponse{
+		Items:  items,
+		Offset: offset,
+		Limit:  limit,
+	}
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"net/http"
+	"nstu/internal/api/apierror"
 	"nstu/internal/api/handler"
 	"nstu/internal/api/middleware"
 
@@ -9,21 +11,31 @@ import (
 )
 
 // NewRouter создает новый маршрутизатор с использованием заданных параметров
-func NewRouter(h *handler.Handler, rateLimit, burstLimit int) *mux.Router {
+func NewRouter(h *handler.Handler, authConf middleware.AuthConfig, rateLimit, burstLimit int) *mux.Router {
 	r := mux.NewRouter()
-
-	// Global middleware
-	r.Use(middleware.LoggerMiddleware())                                     // 1 - логируем все
-	r.Use(middleware.RateLimitMiddleware(rate.Limit(rateLimit), burstLimit)) // 2 - проверяем лимиты
-	r.Use(middleware.CORSMiddleware())                                       // 3 - настраиваем CORS
-	r.Use(middleware.RecoverMiddleware())                                    // 4 - перехватываем панику
+	r.NotFoundHandler = apierror.NotFoundHandler()
+	r.MethodNotAllowedHandler = apierror.MethodNotAllowedHandler()
 
 	// API routes
 	api := r.PathPrefix("/api/v1").Subrouter()
-	api.Use(middleware.AuthMiddleware()) // 5 - проверяем авторизацию
+
+	// API middleware. Подключаются к подмаршрутизатору, чтобы не затрагивать webhook бота
+	api.Use(middleware.LoggerMiddleware())                                     // 1 - логируем все
+	api.Use(middleware.RateLimitMiddleware(rate.Limit(rateLimit), burstLimit)) // 2 - проверяем лимиты
+	api.Use(middleware.CORSMiddleware())                                       // 3 - настраиваем CORS
+	api.Use(middleware.RecoverMiddleware())                                    // 4 - перехватываем панику
+	api.Use(middleware.AuthMiddleware(authConf))                               // 5 - проверяем авторизацию
 
 	// Регистрация маршрутов
 	h.RegisterRoutes(api)
 
 	return r
 }
+
+// RegisterWebhook регистрирует обработчик webhook бота Telegram.
+// Запросы приходят с серверов Telegram без initData, поэтому лимиты по IP, CORS и
+// авторизация Mini App к ним не применяются; подлинность проверяет сам обработчик по secret_token.
+func RegisterWebhook(r *mux.Router, path string, webhook http.Handler) {
+	h := middleware.LoggerMiddleware()(middleware.RecoverMiddleware()(webhook))
+	r.Handle(path, h).Methods(http.MethodPost)
+}
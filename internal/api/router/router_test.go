@@ -0,0 +1,65 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"nstu/internal/api/handler"
+	"testing"
+	"time"
+)
+
+type testAuthConfig struct{}
+
+func (testAuthConfig) GetToken() string             { return "123456:test-token" }
+func (testAuthConfig) GetAuthMaxAge() time.Duration { return time.Hour }
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		method string
+	}{
+		{name: "new form", path: "/api/v1/form", method: http.MethodPost},
+		{name: "list forms", path: "/api/v1/forms", method: http.MethodGet},
+		{name: "update form", path: "/api/v1/forms/1", method: http.MethodPut},
+		{name: "withdraw form", path: "/api/v1/forms/1", method: http.MethodDelete},
+	}
+
+	// Preflight отвечает CORS middleware до авторизации, сервис не вызывается
+	r := NewRouter(handler.NewHandler(nil), testAuthConfig{}, 100, 100)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+				t.Errorf("Access-Control-Allow-Origin = %q", got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization" {
+				t.Errorf("Access-Control-Allow-Headers = %q", got)
+			}
+		})
+	}
+}
+
+func TestUnauthorizedRequestHasCORS(t *testing.T) {
+	r := NewRouter(handler.NewHandler(nil), testAuthConfig{}, 100, 100)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/forms", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+}
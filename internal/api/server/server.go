@@ -2,11 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"nstu/internal/logger"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 )
 
@@ -26,35 +24,19 @@ func NewServer(handler http.Handler, addr string) *Server {
 	}
 }
 
-// RunUntilSignal запускает сервер и ждет сигнала для graceful shutdown
-func (s *Server) RunUntilSignal() error {
-	// Канал для сигналов
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
-
-	// Канал для ошибок
-	errChan := make(chan error, 1)
-
-	// Запускаем сервер в горутине
-	go func() {
-		logger.Log.Info().Msg("Сервер запущен: " + s.httpServer.Addr)
-		if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-
-	// Ждем либо сигнал завершения, либо ошибку
-	select {
-	case <-quit:
-		logger.Log.Info().Msg("Получен сигнал завершения")
-	case err := <-errChan:
+// Start запускает сервер и блокируется до его остановки.
+// После вызова Shutdown возвращает nil.
+func (s *Server) Start() error {
+	logger.Log.Info().Msg("Сервер запущен: " + s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
+	return nil
+}
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+// Shutdown перестает принимать новые соединения и ждет завершения текущих запросов,
+// но не дольше дедлайна ctx
+func (s *Server) Shutdown(ctx context.Context) error {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		logger.Log.Error().Err(err).Msg("Ошибка при остановке сервера")
 		return err
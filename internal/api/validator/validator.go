@@ -0,0 +1,87 @@
+// Package validator проверяет DTO API по тегам validate
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/api/apierror"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// get возвращает общий экземпляр валидатора. Валидатор кеширует
+// информацию о структурах, поэтому создается один раз.
+func get() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		// В ошибках используем имена полей из JSON, их видит клиент
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+	})
+	return validate
+}
+
+// Validate проверяет структуру по тегам validate.
+// Ошибки валидации возвращаются как *apierror.Error с описанием полей.
+func Validate(s interface{}) error {
+	err := get().Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apierror.Internal(err)
+	}
+
+	details := make([]apierror.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		details = append(details, apierror.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return apierror.Validation(details...)
+}
+
+// Struct проверяет структуру общим валидатором и возвращает ошибки validator без преобразования.
+// Через Struct и Var заявки проверяет бот, чтобы правила API и бота не расходились.
+func Struct(s interface{}) error {
+	return get().Struct(s)
+}
+
+// Var проверяет значение по тегу validate общим валидатором
+func Var(value interface{}, tag string) error {
+	return get().Var(value, tag)
+}
+
+// fieldMessage формирует описание ошибки поля
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "Field is required"
+	case "max":
+		return fmt.Sprintf("Must be at most %s characters long", fe.Param())
+	case "min":
+		return fmt.Sprintf("Must be at least %s characters long", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("Must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("Failed on the %q rule", fe.Tag())
+	}
+}
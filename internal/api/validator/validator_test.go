@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"errors"
+	"nstu/internal/api/apierror"
+	"nstu/internal/api/request"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		req  request.FormRequest
+		want []apierror.FieldError
+	}{
+		{
+			name: "valid",
+			req:  request.FormRequest{Name: "Ivan", Feedback: "@ivan", Comment: "Вопрос"},
+		},
+		{
+			name: "missing name",
+			req:  request.FormRequest{Comment: "Вопрос"},
+			want: []apierror.FieldError{{Field: "name", Rule: "required", Message: "Field is required"}},
+		},
+		{
+			name: "too long fields",
+			req:  request.FormRequest{Name: "Ivan", Feedback: strings.Repeat("a", 257), Comment: strings.Repeat("a", 513)},
+			want: []apierror.FieldError{
+				{Field: "feedback", Rule: "max", Param: "256", Message: "Must be at most 256 characters long"},
+				{Field: "comment", Rule: "max", Param: "512", Message: "Must be at most 512 characters long"},
+			},
+		},
+		{
+			name: "limits in characters",
+			req:  request.FormRequest{Name: strings.Repeat("я", 128)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) || apiErr.Kind != apierror.KindValidation {
+				t.Fatalf("Validate() = %v, want validation error", err)
+			}
+			if !reflect.DeepEqual(apiErr.Details, tt.want) {
+				t.Errorf("details = %+v, want %+v", apiErr.Details, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNotStruct(t *testing.T) {
+	var apiErr *apierror.Error
+	if err := Validate("form"); !errors.As(err, &apiErr) || apiErr.Kind != apierror.KindInternal {
+		t.Errorf("Validate(string) = %v, want internal error", err)
+	}
+}
+
+func TestVar(t *testing.T) {
+	tests := []struct {
+		value string
+		tag   string
+		ok    bool
+	}{
+		{value: "Ivan", tag: "required,max=128", ok: true},
+		{value: "", tag: "required,max=128", ok: false},
+		{value: strings.Repeat("я", 129), tag: "required,max=128", ok: false},
+		{value: "", tag: "max=256", ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if err := Var(tt.value, tt.tag); (err == nil) != tt.ok {
+				t.Errorf("Var(%q, %q) = %v, want ok %v", tt.value, tt.tag, err, tt.ok)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ type Api struct {
 	Port         string `envconfig:"API_PORT" required:"true"`
 	LimiterRate  int    `envconfig:"LIMITER_RATE" default:"5"`
 	LimiterBurst int    `envconfig:"LIMITER_BURST" default:"10"`
+
+	ShutdownTimeoutRow int `envconfig:"API_SHUTDOWN_TIMEOUT_SECONDS" default:"15"`
 }
 
 func (c *Api) URL() string {
@@ -27,6 +29,11 @@ func (c *Api) GetLimiterBurst() int {
 	return c.LimiterBurst
 }
 
+// GetShutdownTimeout возвращает время на корректную остановку сервера и бота
+func (c *Api) GetShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutRow) * time.Second
+}
+
 // Database конфигурация базы данных
 
 type Database struct {
@@ -55,6 +62,16 @@ type Telegram struct {
 	ExpirationRow      int    `envconfig:"TG_EXPIRATION_HOURS" required:"true"`
 	CleanupIntervalRow int    `envconfig:"TG_CLEANUP_INTERVAL_MINUTES" required:"true"`
 	MessageChatsRow    string `envconfig:"TG_MESSAGE_CHATS" required:"true"`
+	AuthMaxAgeRow      int    `envconfig:"TG_AUTH_MAX_AGE_MINUTES" default:"1440"`
+	UpdateMode         string `envconfig:"TG_UPDATE_MODE" default:"polling"`
+	WebhookURL         string `envconfig:"TG_WEBHOOK_URL"`
+	WebhookSecret      string `envconfig:"TG_WEBHOOK_SECRET"`
+	WebAppURL          string `envconfig:"TG_WEBAPP_URL"`
+	StateStore         string `envconfig:"TG_STATE_STORE" default:"memory"`
+	StateFile          string `envconfig:"TG_STATE_FILE" default:"bot_states.json"`
+	Workers            int    `envconfig:"TG_WORKERS" default:"16"`
+	QueueSize          int    `envconfig:"TG_QUEUE_SIZE" default:"100"`
+	QueueOverflow      string `envconfig:"TG_QUEUE_OVERFLOW" default:"block"`
 
 	MessageChats    []int64       `ignored:"true"`
 	Expiration      time.Duration `ignored:"true"`
@@ -94,3 +111,63 @@ func (c *Telegram) GetExpiration() time.Duration {
 func (c *Telegram) GetCleanupInterval() time.Duration {
 	return time.Duration(c.CleanupIntervalRow) * time.Minute
 }
+
+// GetAuthMaxAge возвращает максимальный возраст initData (auth_date).
+// Значение 0 отключает проверку срока жизни.
+func (c *Telegram) GetAuthMaxAge() time.Duration {
+	return time.Duration(c.AuthMaxAgeRow) * time.Minute
+}
+
+// GetUpdateMode возвращает способ получения обновлений: polling или webhook
+func (c *Telegram) GetUpdateMode() string {
+	return c.UpdateMode
+}
+
+// GetWebhookURL возвращает публичный адрес webhook
+func (c *Telegram) GetWebhookURL() string {
+	return c.WebhookURL
+}
+
+// GetWebhookPath возвращает путь из адреса webhook для регистрации в маршрутизаторе
+func (c *Telegram) GetWebhookPath() string {
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// GetWebhookSecret возвращает secret_token, которым Telegram подписывает запросы webhook
+func (c *Telegram) GetWebhookSecret() string {
+	return c.WebhookSecret
+}
+
+// GetWebAppURL возвращает адрес Mini App для кнопки в меню бота. Пустой адрес - кнопки нет
+func (c *Telegram) GetWebAppURL() string {
+	return c.WebAppURL
+}
+
+// GetStateStore возвращает хранилище состояний пользователей бота: memory, postgres или file
+func (c *Telegram) GetStateStore() string {
+	return c.StateStore
+}
+
+// GetStateFile возвращает путь к файлу состояний для хранилища file
+func (c *Telegram) GetStateFile() string {
+	return c.StateFile
+}
+
+// GetWorkers возвращает количество параллельных обработчиков обновлений бота
+func (c *Telegram) GetWorkers() int {
+	return c.Workers
+}
+
+// GetQueueSize возвращает длину очереди обновлений одного обработчика
+func (c *Telegram) GetQueueSize() int {
+	return c.QueueSize
+}
+
+// GetQueueOverflow возвращает поведение при заполненной очереди: block или drop
+func (c *Telegram) GetQueueOverflow() string {
+	return c.QueueOverflow
+}
@@ -31,13 +31,95 @@ type User struct {
 // Form заявка оставленная пользователем
 type Form struct {
 	BaseModel
-	UserID   int64  `json:"-" db:"user_id" sql:"not null,references:users(id),index"`                    // id пользователя
-	Name     string `json:"name" db:"name" sql:"not null,type:varchar(128)" validate:"required,max=128"` // Имя пользователя
-	Feedback string `json:"feedback" db:"feedback" sql:"type:varchar(256)" validate:"max=256"`           // Предпочтительный способ обратной связи
-	Comment  string `json:"comment" db:"comment" sql:"type:varchar(512)" validate:"max=512"`             // Комментарий к заявке
+	UserID   int64      `json:"-" db:"user_id" sql:"not null,references:users(id),index"`                    // id пользователя
+	Name     string     `json:"name" db:"name" sql:"not null,type:varchar(128)" validate:"required,max=128"` // Имя пользователя
+	Feedback string     `json:"feedback" db:"feedback" sql:"type:varchar(256)" validate:"max=256"`           // Предпочтительный способ обратной связи
+	Comment  string     `json:"comment" db:"comment" sql:"type:varchar(512)" validate:"max=512"`             // Комментарий к заявке
+	Status   FormStatus `json:"status" db:"status" sql:"not null,type:varchar(32),default:new,index"`        // Статус заявки
+	Source   string     `json:"-" db:"source" sql:"not null,type:varchar(64),default:'',index"`              // Источник трафика из ссылки на бота
+	Assignee *int64     `json:"-" db:"assignee_id" sql:"references:users(id)"`                               // id оператора, взявшего заявку в работу
+}
+
+// FormStatus статус заявки
+type FormStatus string
+
+const (
+	FormStatusNew            FormStatus = "new"              // Новая заявка
+	FormStatusInProgress     FormStatus = "in_progress"      // Взята в работу оператором
+	FormStatusWaitingForUser FormStatus = "waiting_for_user" // Ожидает ответа пользователя
+	FormStatusResolved       FormStatus = "resolved"         // Решена
+	FormStatusRejected       FormStatus = "rejected"         // Отклонена
+	FormStatusWithdrawn      FormStatus = "withdrawn"        // Отозвана пользователем
+)
+
+// FormStatuses все статусы заявки
+var FormStatuses = []FormStatus{
+	FormStatusNew,
+	FormStatusInProgress,
+	FormStatusWaitingForUser,
+	FormStatusResolved,
+	FormStatusRejected,
+	FormStatusWithdrawn,
+}
+
+// Valid проверяет, что статус известен
+func (s FormStatus) Valid() bool {
+	for _, status := range FormStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Open возвращает true, если работа по заявке не завершена
+func (s FormStatus) Open() bool {
+	return s == FormStatusNew || s == FormStatusInProgress || s == FormStatusWaitingForUser
+}
+
+// FormStatusChange запись истории изменения статуса заявки
+type FormStatusChange struct {
+	ID         int64      `json:"id" db:"id" sql:"primary key,autoincrement"`
+	FormID     int64      `json:"formId" db:"form_id" sql:"not null,references:forms(id),index"`
+	FromStatus FormStatus `json:"fromStatus" db:"from_status" sql:"not null,type:varchar(32)"`
+	ToStatus   FormStatus `json:"toStatus" db:"to_status" sql:"not null,type:varchar(32)"`
+	ChangedBy  int64      `json:"changedBy" db:"changed_by" sql:"not null"` // ID пользователя Telegram, изменившего статус
+	ChangedAt  time.Time  `json:"changedAt" db:"changed_at" sql:"not null,default:current_timestamp"`
 }
 
 type Request struct {
-	Form Form
-	User User
+	Form     Form
+	User     User
+	Assignee *User // Оператор, взявший заявку в работу. nil, если заявка не назначена
+}
+
+// FormNotification уведомление о заявке, отправленное в чат администраторов
+type FormNotification struct {
+	ChatID    int64     `db:"chat_id" sql:"primary key"`
+	MessageID int       `db:"message_id" sql:"primary key"`
+	FormID    int64     `db:"form_id" sql:"not null,references:forms(id),index"`
+	CreatedAt time.Time `db:"created_at" sql:"not null,default:current_timestamp"`
+}
+
+// FormMessageDirection автор сообщения в переписке по заявке
+type FormMessageDirection string
+
+const (
+	FormMessageFromOperator FormMessageDirection = "operator" // Сообщение оператора пользователю
+	FormMessageFromUser     FormMessageDirection = "user"     // Сообщение пользователя оператору
+)
+
+// FormMessage сообщение переписки по заявке.
+// Каждое сообщение существует в двух чатах: в чате администраторов и в личном чате пользователя с ботом.
+type FormMessage struct {
+	ID             int64                `json:"id" db:"id" sql:"primary key,autoincrement"`
+	FormID         int64                `json:"formId" db:"form_id" sql:"not null,references:forms(id),index"`
+	AuthorID       int64                `json:"authorId" db:"author_id" sql:"not null"`
+	Direction      FormMessageDirection `json:"direction" db:"direction" sql:"not null,type:varchar(16)"`
+	Text           string               `json:"text" db:"text" sql:"not null,type:varchar(4096)"`
+	AdminChatID    int64                `json:"-" db:"admin_chat_id" sql:"not null"`
+	AdminMessageID int                  `json:"-" db:"admin_message_id" sql:"not null"`
+	UserChatID     int64                `json:"-" db:"user_chat_id" sql:"not null"`
+	UserMessageID  int                  `json:"-" db:"user_message_id" sql:"not null"`
+	CreatedAt      time.Time            `json:"createdAt" db:"created_at" sql:"not null,default:current_timestamp"`
 }
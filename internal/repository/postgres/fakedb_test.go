@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fakeCall ожидаемый запрос к fakeDB и его результат.
+// Аргументы проходят стандартную проверку database/sql, поэтому структуры
+// отклоняются так же, как в lib/pq, а результат сканируется по правилам database/sql.
+type fakeCall struct {
+	query    string           // Фрагмент текста запроса
+	columns  []string         // Колонки результата запроса
+	rows     [][]driver.Value // Строки результата запроса
+	affected int64            // Затронутые строки для Exec
+	err      error            // Ошибка выполнения запроса
+
+	args []driver.Value // Аргументы, с которыми был выполнен запрос
+}
+
+// fakeDB база данных, отвечающая на запросы по списку fakeCall в порядке вызова
+type fakeDB struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	calls     []*fakeCall
+	next      int
+	commits   int
+	rollbacks int
+}
+
+// newFakeDB создает *sqlx.DB поверх fakeDB с ожидаемыми запросами calls
+func newFakeDB(t *testing.T, calls ...*fakeCall) (*sqlx.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{t: t, calls: calls}
+	db := sqlx.NewDb(sql.OpenDB(fake), "postgres")
+	t.Cleanup(func() {
+		db.Close()
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if fake.next != len(fake.calls) {
+			t.Errorf("executed %d of %d expected queries", fake.next, len(fake.calls))
+		}
+	})
+	return db, fake
+}
+
+// call возвращает следующий ожидаемый запрос и запоминает аргументы
+func (f *fakeDB) call(query string, args []driver.NamedValue) (*fakeCall, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.next >= len(f.calls) {
+		f.t.Errorf("unexpected query: %s", query)
+		return nil, fmt.Errorf("unexpected query")
+	}
+	c := f.calls[f.next]
+	f.next++
+
+	if !strings.Contains(strings.Join(strings.Fields(query), " "), c.query) {
+		f.t.Errorf("query %q does not contain %q", query, c.query)
+	}
+	for _, arg := range args {
+		c.args = append(c.args, arg.Value)
+	}
+	return c, c.err
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fake driver is opened through connector")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	call, err := c.db.call(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: call.columns, rows: call.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	call, err := c.db.call(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(call.affected), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
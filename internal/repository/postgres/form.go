@@ -2,8 +2,11 @@
 package postgres
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"nstu/internal/model"
+	"nstu/internal/repository"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -21,28 +24,37 @@ func NewFormRepo(db *sqlx.DB) *FormRepo {
 // CreateForm создает заявку
 func (r *FormRepo) CreateForm(form *model.Form) error {
 	query := `
-		INSERT INTO forms (user_id, name, feedback, comment)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO forms (user_id, name, feedback, comment, status, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, updated_at`
 
+	if form.Status == "" {
+		form.Status = model.FormStatusNew
+	}
+
 	return r.db.QueryRow(
 		query,
 		form.UserID,
 		form.Name,
 		form.Feedback,
 		form.Comment,
-	).Scan(&form.ID, &form.UpdatedAt)
+		form.Status,
+		form.Source,
+	).Scan(&form.ID.ID, &form.UpdatedAt.UpdatedAt)
 }
 
 // GetFormByID получает заявку по id
 func (r *FormRepo) GetFormByID(id int64) (*model.Form, error) {
 	form := &model.Form{}
 	query := `
-		SELECT id, user_id, name, feedback, comment, updated_at
+		SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at
 		FROM forms
 		WHERE id = $1`
 
 	err := r.db.Get(form, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get form: %w", err)
 	}
@@ -50,21 +62,28 @@ func (r *FormRepo) GetFormByID(id int64) (*model.Form, error) {
 	return form, nil
 }
 
-// UpdateForm обновляет заявку
+// UpdateForm обновляет поля заявки автора form.UserID, пока работа по ней не завершена.
+// Если заявка закрыта или принадлежит другому пользователю, возвращается repository.ErrConflict:
+// статус мог измениться после проверки в сервисе.
 func (r *FormRepo) UpdateForm(form *model.Form) error {
 	query := `
 		UPDATE forms
 		SET name = $2, feedback = $3, comment = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $5 AND status IN ('new', 'in_progress', 'waiting_for_user')
 		RETURNING updated_at`
 
-	return r.db.QueryRow(
+	err := r.db.QueryRow(
 		query,
-		form.ID,
+		form.ID.ID,
 		form.Name,
 		form.Feedback,
 		form.Comment,
-	).Scan(&form.UpdatedAt)
+		form.UserID,
+	).Scan(&form.UpdatedAt.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrConflict
+	}
+	return err
 }
 
 // DeleteForm удаляет заявку
@@ -81,7 +100,7 @@ func (r *FormRepo) DeleteForm(id int64) error {
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("form not found")
+		return repository.ErrNotFound
 	}
 
 	return nil
@@ -91,7 +110,7 @@ func (r *FormRepo) DeleteForm(id int64) error {
 func (r *FormRepo) ListForms(offset, limit int, userID int64) ([]model.Form, error) {
 	forms := []model.Form{}
 	query := `
-		SELECT id, user_id, name, feedback, comment, updated_at
+		SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at
 		FROM forms
 		WHERE ($1 = 0 OR user_id = $1)
 		ORDER BY updated_at DESC
@@ -104,3 +123,120 @@ func (r *FormRepo) ListForms(offset, limit int, userID int64) ([]model.Form, err
 
 	return forms, nil
 }
+
+// ListFormsByStatus получает список заявок с указанным статусом, начиная с самых старых
+func (r *FormRepo) ListFormsByStatus(status model.FormStatus, offset, limit int) ([]model.Form, error) {
+	forms := []model.Form{}
+	query := `
+		SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at
+		FROM forms
+		WHERE status = $1
+		ORDER BY updated_at ASC
+		LIMIT $2 OFFSET $3`
+
+	err := r.db.Select(&forms, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list forms by status: %w", err)
+	}
+
+	return forms, nil
+}
+
+// UpdateFormStatus меняет статус заявки и записывает изменение в историю.
+// Статус меняется только если текущий статус заявки равен change.FromStatus,
+// иначе возвращается repository.ErrConflict.
+func (r *FormRepo) UpdateFormStatus(change *model.FormStatusChange) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateFormStatus(tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateFormStatusAndAssignee меняет статус заявки, записывает изменение в историю
+// и назначает оператора в одной транзакции. Условия изменения статуса как в UpdateFormStatus.
+func (r *FormRepo) UpdateFormStatusAndAssignee(change *model.FormStatusChange, assigneeID int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateFormStatus(tx, change); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE forms
+		SET assignee_id = $2
+		WHERE id = $1`,
+		change.FormID,
+		assigneeID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to assign form: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// updateFormStatus меняет статус заявки и записывает изменение в историю в транзакции tx
+func updateFormStatus(tx *sqlx.Tx, change *model.FormStatusChange) error {
+	result, err := tx.Exec(`
+		UPDATE forms
+		SET status = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $2`,
+		change.FormID,
+		change.FromStatus,
+		change.ToStatus,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update form status: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return repository.ErrConflict
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO form_status_history (form_id, from_status, to_status, changed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, changed_at`,
+		change.FormID,
+		change.FromStatus,
+		change.ToStatus,
+		change.ChangedBy,
+	).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save form status history: %w", err)
+	}
+
+	return nil
+}
+
+// GetFormStatusHistory получает историю изменения статусов заявки
+func (r *FormRepo) GetFormStatusHistory(formID int64) ([]model.FormStatusChange, error) {
+	history := []model.FormStatusChange{}
+	query := `
+		SELECT id, form_id, from_status, to_status, changed_by, changed_at
+		FROM form_status_history
+		WHERE form_id = $1
+		ORDER BY changed_at ASC, id ASC`
+
+	err := r.db.Select(&history, query, formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form status history: %w", err)
+	}
+
+	return history, nil
+}
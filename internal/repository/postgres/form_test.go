@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+	"nstu/internal/service"
+	"testing"
+	"time"
+)
+
+// insertFormCall ожидаемая вставка заявки, возвращающая id и updated_at
+func insertFormCall(id int64, updatedAt time.Time) *fakeCall {
+	return &fakeCall{
+		query:   "INSERT INTO forms (user_id, name, feedback, comment, status, source) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, updated_at",
+		columns: []string{"id", "updated_at"},
+		rows:    [][]driver.Value{{id, updatedAt}},
+	}
+}
+
+func TestFormRepoCreateForm(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	call := insertFormCall(7, updatedAt)
+	db, _ := newFakeDB(t, call)
+
+	form := &model.Form{
+		UserID:   42,
+		Name:     "Ivan",
+		Feedback: "ivan@example.com",
+		Comment:  "Вопрос о поступлении",
+		Status:   model.FormStatusNew,
+	}
+	if err := NewFormRepo(db).CreateForm(form); err != nil {
+		t.Fatalf("CreateForm failed: %v", err)
+	}
+
+	assertArgs(t, call.args, []driver.Value{int64(42), "Ivan", "ivan@example.com", "Вопрос о поступлении", "new", ""})
+	if form.ID.ID != 7 {
+		t.Errorf("id = %d, want 7", form.ID.ID)
+	}
+	if !form.UpdatedAt.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("updated_at = %v, want %v", form.UpdatedAt.UpdatedAt, updatedAt)
+	}
+}
+
+// TestCreateFormEndToEnd проходит путь заявки из API и бота: сервис сохраняет пользователя
+// и заявку через репозиторий Postgres и отправляет ее в канал уведомлений
+func TestCreateFormEndToEnd(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	userCall := &fakeCall{
+		query:   "INSERT INTO users (id, first_name, last_name, username) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) ON CONFLICT (id) DO UPDATE",
+		columns: []string{"updated_at"},
+		rows:    [][]driver.Value{{updatedAt}},
+	}
+	formCall := insertFormCall(7, updatedAt)
+	db, _ := newFakeDB(t, userCall, formCall)
+
+	srv := service.NewService(NewRepository(db))
+	user := &model.User{ID: 42, FirstName: "Ivan"}
+	form := &model.Form{Name: "Ivan", Comment: "Вопрос", Source: "vk_spring"}
+	if err := srv.CreateForm(user, form); err != nil {
+		t.Fatalf("CreateForm failed: %v", err)
+	}
+
+	assertArgs(t, userCall.args, []driver.Value{int64(42), "Ivan", "", ""})
+	assertArgs(t, formCall.args, []driver.Value{int64(42), "Ivan", "", "Вопрос", "new", "vk_spring"})
+
+	select {
+	case request := <-srv.GetMessageChan():
+		if request.Form.ID.ID != 7 || request.User.ID != 42 {
+			t.Errorf("unexpected notification: form %d, user %d", request.Form.ID.ID, request.User.ID)
+		}
+	default:
+		t.Error("form was not sent to notification channel")
+	}
+}
+
+func TestFormRepoUpdateForm(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 2, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		wantErr error
+	}{
+		{name: "updated", rows: [][]driver.Value{{updatedAt}}},
+		{name: "closed or not owned", rows: nil, wantErr: repository.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := &fakeCall{
+				query:   "UPDATE forms SET name = $2, feedback = $3, comment = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $5 AND status IN ('new', 'in_progress', 'waiting_for_user') RETURNING updated_at",
+				columns: []string{"updated_at"},
+				rows:    tt.rows,
+			}
+			db, _ := newFakeDB(t, call)
+
+			form := &model.Form{UserID: 42, Name: "Ivan", Feedback: "@ivan", Comment: "Новый вопрос"}
+			form.ID.ID = 7
+			err := NewFormRepo(db).UpdateForm(form)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateForm error = %v, want %v", err, tt.wantErr)
+			}
+
+			assertArgs(t, call.args, []driver.Value{int64(7), "Ivan", "@ivan", "Новый вопрос", int64(42)})
+			if tt.wantErr == nil && !form.UpdatedAt.UpdatedAt.Equal(updatedAt) {
+				t.Errorf("updated_at = %v, want %v", form.UpdatedAt.UpdatedAt, updatedAt)
+			}
+		})
+	}
+}
+
+func TestFormRepoCreateFormInitialStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status model.FormStatus
+		want   model.FormStatus
+	}{
+		{name: "default", status: "", want: model.FormStatusNew},
+		{name: "explicit", status: model.FormStatusNew, want: model.FormStatusNew},
+		{name: "preset", status: model.FormStatusInProgress, want: model.FormStatusInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := insertFormCall(7, time.Now())
+			db, _ := newFakeDB(t, call)
+
+			form := &model.Form{UserID: 42, Name: "Ivan", Status: tt.status}
+			if err := NewFormRepo(db).CreateForm(form); err != nil {
+				t.Fatalf("CreateForm failed: %v", err)
+			}
+
+			if call.args[4] != string(tt.want) {
+				t.Errorf("inserted status = %v, want %s", call.args[4], tt.want)
+			}
+			if form.Status != tt.want {
+				t.Errorf("form status = %s, want %s", form.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormRepoUpdateFormStatus(t *testing.T) {
+	changedAt := time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantErr   error
+		commits   int
+		rollbacks int
+	}{
+		{name: "changed", affected: 1, commits: 1},
+		{name: "conflict", affected: 0, wantErr: repository.ErrConflict, rollbacks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := &fakeCall{
+				query:    "UPDATE forms SET status = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = $2",
+				affected: tt.affected,
+			}
+			calls := []*fakeCall{update}
+			history := &fakeCall{
+				query:   "INSERT INTO form_status_history (form_id, from_status, to_status, changed_by)",
+				columns: []string{"id", "changed_at"},
+				rows:    [][]driver.Value{{int64(3), changedAt}},
+			}
+			if tt.wantErr == nil {
+				calls = append(calls, history)
+			}
+			db, fake := newFakeDB(t, calls...)
+
+			change := &model.FormStatusChange{
+				FormID:     7,
+				FromStatus: model.FormStatusNew,
+				ToStatus:   model.FormStatusInProgress,
+				ChangedBy:  100,
+			}
+			err := NewFormRepo(db).UpdateFormStatus(change)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateFormStatus error = %v, want %v", err, tt.wantErr)
+			}
+
+			assertArgs(t, update.args, []driver.Value{int64(7), "new", "in_progress"})
+			if tt.wantErr == nil {
+				assertArgs(t, history.args, []driver.Value{int64(7), "new", "in_progress", int64(100)})
+				if change.ID != 3 || !change.ChangedAt.Equal(changedAt) {
+					t.Errorf("history row = %d at %v", change.ID, change.ChangedAt)
+				}
+			}
+			if fake.commits != tt.commits || fake.rollbacks != tt.rollbacks {
+				t.Errorf("commits = %d, rollbacks = %d, want %d and %d", fake.commits, fake.rollbacks, tt.commits, tt.rollbacks)
+			}
+		})
+	}
+}
+
+func TestFormRepoUpdateFormStatusAndAssignee(t *testing.T) {
+	tests := []struct {
+		name      string
+		assignErr error
+		wantErr   bool
+		commits   int
+		rollbacks int
+	}{
+		{name: "taken", commits: 1},
+		{name: "assign failed", assignErr: errors.New("connection reset"), wantErr: true, rollbacks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := &fakeCall{query: "UPDATE forms SET status = $3", affected: 1}
+			history := &fakeCall{
+				query:   "INSERT INTO form_status_history",
+				columns: []string{"id", "changed_at"},
+				rows:    [][]driver.Value{{int64(1), time.Now()}},
+			}
+			assign := &fakeCall{query: "UPDATE forms SET assignee_id = $2 WHERE id = $1", affected: 1, err: tt.assignErr}
+			db, fake := newFakeDB(t, update, history, assign)
+
+			change := &model.FormStatusChange{
+				FormID:     7,
+				FromStatus: model.FormStatusNew,
+				ToStatus:   model.FormStatusInProgress,
+				ChangedBy:  100,
+			}
+			err := NewFormRepo(db).UpdateFormStatusAndAssignee(change, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateFormStatusAndAssignee error = %v, want error %v", err, tt.wantErr)
+			}
+
+			assertArgs(t, assign.args, []driver.Value{int64(7), int64(100)})
+			// Без назначения не сохраняются ни статус, ни запись истории
+			if fake.commits != tt.commits || fake.rollbacks != tt.rollbacks {
+				t.Errorf("commits = %d, rollbacks = %d, want %d and %d", fake.commits, fake.rollbacks, tt.commits, tt.rollbacks)
+			}
+		})
+	}
+}
+
+// formColumns колонки заявки в запросах чтения
+var formColumns = []string{"id", "user_id", "name", "feedback", "comment", "status", "source", "assignee_id", "updated_at"}
+
+func TestFormRepoSource(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := []driver.Value{int64(7), int64(42), "Ivan", "", "Вопрос", "new", "vk_spring", nil, updatedAt}
+
+	t.Run("create", func(t *testing.T) {
+		call := insertFormCall(7, updatedAt)
+		db, _ := newFakeDB(t, call)
+
+		form := &model.Form{UserID: 42, Name: "Ivan", Source: "vk_spring"}
+		if err := NewFormRepo(db).CreateForm(form); err != nil {
+			t.Fatalf("CreateForm failed: %v", err)
+		}
+		if call.args[5] != "vk_spring" {
+			t.Errorf("inserted source = %v, want vk_spring", call.args[5])
+		}
+	})
+
+	reads := []struct {
+		name  string
+		query string
+		read  func(r *FormRepo) ([]model.Form, error)
+	}{
+		{
+			name:  "get by id",
+			query: "SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at FROM forms WHERE id = $1",
+			read: func(r *FormRepo) ([]model.Form, error) {
+				form, err := r.GetFormByID(7)
+				if err != nil {
+					return nil, err
+				}
+				return []model.Form{*form}, nil
+			},
+		},
+		{
+			name:  "list",
+			query: "SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at FROM forms WHERE ($1 = 0 OR user_id = $1)",
+			read:  func(r *FormRepo) ([]model.Form, error) { return r.ListForms(0, 10, 42) },
+		},
+		{
+			name:  "list by status",
+			query: "SELECT id, user_id, name, feedback, comment, status, source, assignee_id, updated_at FROM forms WHERE status = $1",
+			read:  func(r *FormRepo) ([]model.Form, error) { return r.ListFormsByStatus(model.FormStatusNew, 0, 10) },
+		},
+	}
+
+	for _, tt := range reads {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newFakeDB(t, &fakeCall{query: tt.query, columns: formColumns, rows: [][]driver.Value{row}})
+
+			forms, err := tt.read(NewFormRepo(db))
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if len(forms) != 1 || forms[0].Source != "vk_spring" || forms[0].ID.ID != 7 || forms[0].Assignee != nil {
+				t.Errorf("forms = %+v", forms)
+			}
+		})
+	}
+}
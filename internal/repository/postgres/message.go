@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MessageRepo структура для работы с перепиской по заявкам
+type MessageRepo struct {
+	db *sqlx.DB
+}
+
+// NewMessageRepo - создает новый репозиторий для работы с перепиской
+func NewMessageRepo(db *sqlx.DB) *MessageRepo {
+	return &MessageRepo{db: db}
+}
+
+// CreateFormMessage сохраняет сообщение переписки
+func (r *MessageRepo) CreateFormMessage(message *model.FormMessage) error {
+	query := `
+		INSERT INTO form_messages (form_id, author_id, direction, text, admin_chat_id, admin_message_id, user_chat_id, user_message_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	return r.db.QueryRow(
+		query,
+		message.FormID,
+		message.AuthorID,
+		message.Direction,
+		message.Text,
+		message.AdminChatID,
+		message.AdminMessageID,
+		message.UserChatID,
+		message.UserMessageID,
+	).Scan(&message.ID, &message.CreatedAt)
+}
+
+// GetFormMessageByUserMessage получает сообщение переписки по сообщению в личном чате пользователя
+func (r *MessageRepo) GetFormMessageByUserMessage(chatID int64, messageID int) (*model.FormMessage, error) {
+	message := &model.FormMessage{}
+	query := `
+		SELECT id, form_id, author_id, direction, text, admin_chat_id, admin_message_id, user_chat_id, user_message_id, created_at
+		FROM form_messages
+		WHERE user_chat_id = $1 AND user_message_id = $2`
+
+	err := r.db.Get(message, query, chatID, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetFormIDByAdminMessage получает ID заявки по сообщению в чате администраторов:
+// уведомлению о заявке или сообщению переписки
+func (r *MessageRepo) GetFormIDByAdminMessage(chatID int64, messageID int) (int64, error) {
+	var formID int64
+	query := `
+		SELECT form_id FROM form_notifications
+		WHERE chat_id = $1 AND message_id = $2
+		UNION ALL
+		SELECT form_id FROM form_messages
+		WHERE admin_chat_id = $1 AND admin_message_id = $2
+		LIMIT 1`
+
+	err := r.db.Get(&formID, query, chatID, messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repository.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get form by admin message: %w", err)
+	}
+
+	return formID, nil
+}
+
+// ListFormMessages получает переписку по заявке
+func (r *MessageRepo) ListFormMessages(formID int64) ([]model.FormMessage, error) {
+	messages := []model.FormMessage{}
+	query := `
+		SELECT id, form_id, author_id, direction, text, admin_chat_id, admin_message_id, user_chat_id, user_message_id, created_at
+		FROM form_messages
+		WHERE form_id = $1
+		ORDER BY created_at ASC, id ASC`
+
+	err := r.db.Select(&messages, query, formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list form messages: %w", err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestMessageRepoCreate(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	call := &fakeCall{
+		query:   "INSERT INTO form_messages (form_id, author_id, direction, text, admin_chat_id, admin_message_id, user_chat_id, user_message_id)",
+		columns: []string{"id", "created_at"},
+		rows:    [][]driver.Value{{int64(3), createdAt}},
+	}
+	db, _ := newFakeDB(t, call)
+
+	message := &model.FormMessage{
+		FormID:         7,
+		AuthorID:       100,
+		Direction:      model.FormMessageFromOperator,
+		Text:           "Уточните вопрос",
+		AdminChatID:    -100100,
+		AdminMessageID: 15,
+		UserChatID:     42,
+		UserMessageID:  16,
+	}
+	if err := NewMessageRepo(db).CreateFormMessage(message); err != nil {
+		t.Fatalf("CreateFormMessage failed: %v", err)
+	}
+
+	assertArgs(t, call.args, []driver.Value{int64(7), int64(100), string(model.FormMessageFromOperator), "Уточните вопрос", int64(-100100), int64(15), int64(42), int64(16)})
+	if message.ID != 3 || !message.CreatedAt.Equal(createdAt) {
+		t.Errorf("message %d created at %v", message.ID, message.CreatedAt)
+	}
+}
+
+func TestMessageRepoGetFormIDByAdminMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		want    int64
+		wantErr error
+	}{
+		{name: "found", rows: [][]driver.Value{{int64(7)}}, want: 7},
+		{name: "not found", wantErr: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := &fakeCall{
+				query:   "SELECT form_id FROM form_notifications WHERE chat_id = $1 AND message_id = $2 UNION ALL SELECT form_id FROM form_messages",
+				columns: []string{"form_id"},
+				rows:    tt.rows,
+			}
+			db, _ := newFakeDB(t, call)
+
+			formID, err := NewMessageRepo(db).GetFormIDByAdminMessage(-100100, 15)
+			if !errors.Is(err, tt.wantErr) || formID != tt.want {
+				t.Fatalf("GetFormIDByAdminMessage = %d, %v, want %d, %v", formID, err, tt.want, tt.wantErr)
+			}
+			assertArgs(t, call.args, []driver.Value{int64(-100100), int64(15)})
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NotificationRepo структура для работы с уведомлениями о заявках
+type NotificationRepo struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepo - создает новый репозиторий для работы с уведомлениями
+func NewNotificationRepo(db *sqlx.DB) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+// CreateFormNotification сохраняет отправленное уведомление о заявке
+func (r *NotificationRepo) CreateFormNotification(notification *model.FormNotification) error {
+	query := `
+		INSERT INTO form_notifications (chat_id, message_id, form_id)
+		VALUES ($1, $2, $3)
+		RETURNING created_at`
+
+	return r.db.QueryRow(
+		query,
+		notification.ChatID,
+		notification.MessageID,
+		notification.FormID,
+	).Scan(&notification.CreatedAt)
+}
+
+// ListFormNotifications получает все уведомления о заявке
+func (r *NotificationRepo) ListFormNotifications(formID int64) ([]model.FormNotification, error) {
+	notifications := []model.FormNotification{}
+	query := `
+		SELECT chat_id, message_id, form_id, created_at
+		FROM form_notifications
+		WHERE form_id = $1
+		ORDER BY created_at ASC`
+
+	err := r.db.Select(&notifications, query, formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list form notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// GetFormNotification получает уведомление о заявке в указанном чате
+func (r *NotificationRepo) GetFormNotification(formID, chatID int64) (*model.FormNotification, error) {
+	notification := &model.FormNotification{}
+	query := `
+		SELECT chat_id, message_id, form_id, created_at
+		FROM form_notifications
+		WHERE form_id = $1 AND chat_id = $2
+		ORDER BY created_at ASC
+		LIMIT 1`
+
+	err := r.db.Get(notification, query, formID, chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form notification: %w", err)
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestNotificationRepoCreate(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	call := &fakeCall{
+		query:   "INSERT INTO form_notifications (chat_id, message_id, form_id) VALUES ($1, $2, $3) RETURNING created_at",
+		columns: []string{"created_at"},
+		rows:    [][]driver.Value{{createdAt}},
+	}
+	db, _ := newFakeDB(t, call)
+
+	notification := &model.FormNotification{ChatID: -100100, MessageID: 15, FormID: 7}
+	if err := NewNotificationRepo(db).CreateFormNotification(notification); err != nil {
+		t.Fatalf("CreateFormNotification failed: %v", err)
+	}
+
+	assertArgs(t, call.args, []driver.Value{int64(-100100), int64(15), int64(7)})
+	if !notification.CreatedAt.Equal(createdAt) {
+		t.Errorf("created_at = %v, want %v", notification.CreatedAt, createdAt)
+	}
+}
+
+func TestNotificationRepoGet(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		wantErr error
+	}{
+		{name: "found", rows: [][]driver.Value{{int64(-100100), int64(15), int64(7), createdAt}}},
+		{name: "not found", wantErr: repository.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := &fakeCall{
+				query:   "FROM form_notifications WHERE form_id = $1 AND chat_id = $2",
+				columns: []string{"chat_id", "message_id", "form_id", "created_at"},
+				rows:    tt.rows,
+			}
+			db, _ := newFakeDB(t, call)
+
+			notification, err := NewNotificationRepo(db).GetFormNotification(7, -100100)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFormNotification error = %v, want %v", err, tt.wantErr)
+			}
+			assertArgs(t, call.args, []driver.Value{int64(7), int64(-100100)})
+			if err == nil && (notification.MessageID != 15 || notification.FormID != 7) {
+				t.Errorf("notification = %+v", notification)
+			}
+		})
+	}
+}
@@ -27,12 +27,16 @@ func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
 type PostgresRepository struct {
 	*UserRepo
 	*FormRepo
+	*NotificationRepo
+	*MessageRepo
 }
 
 // NewRepository создает новый репозиторий
 func NewRepository(db *sqlx.DB) *PostgresRepository {
 	return &PostgresRepository{
-		UserRepo: NewUserRepo(db),
-		FormRepo: NewFormRepo(db),
+		UserRepo:         NewUserRepo(db),
+		FormRepo:         NewFormRepo(db),
+		NotificationRepo: NewNotificationRepo(db),
+		MessageRepo:      NewMessageRepo(db),
 	}
 }
@@ -21,7 +21,7 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 func (r *UserRepo) CreateUser(user *model.User) error {
 	query := `
 		INSERT INTO users (id, first_name, last_name, username)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
 		RETURNING updated_at`
 
 	return r.db.QueryRow(
@@ -30,16 +30,18 @@ func (r *UserRepo) CreateUser(user *model.User) error {
 		user.FirstName,
 		user.LastName,
 		user.UserName,
-	).Scan(&user.UpdatedAt)
+	).Scan(&user.UpdatedAt.UpdatedAt)
 }
 
-// CreateUserIfNotExists создает пользователя если не существует
+// CreateUserIfNotExists создает пользователя если не существует.
+// Пустые фамилия и username хранятся как NULL: username уникален, а пустая строка
+// у второго пользователя без username нарушила бы ограничение.
 func (r *UserRepo) CreateUserIfNotExists(user *model.User) error {
 	query := `
 		INSERT INTO users (id, first_name, last_name, username)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
 		ON CONFLICT (id) DO UPDATE
-		SET first_name = $2, last_name = $3, username = $4, updated_at = CURRENT_TIMESTAMP
+		SET first_name = $2, last_name = NULLIF($3, ''), username = NULLIF($4, ''), updated_at = CURRENT_TIMESTAMP
 		RETURNING updated_at`
 
 	return r.db.QueryRow(
@@ -48,13 +50,13 @@ func (r *UserRepo) CreateUserIfNotExists(user *model.User) error {
 		user.FirstName,
 		user.LastName,
 		user.UserName,
-	).Scan(&user.UpdatedAt)
+	).Scan(&user.UpdatedAt.UpdatedAt)
 }
 
 func (r *UserRepo) UpdateUser(user *model.User) error {
 	query := `
 		UPDATE users
-		SET first_name = $2, last_name = $3, username = $4, updated_at = CURRENT_TIMESTAMP
+		SET first_name = $2, last_name = NULLIF($3, ''), username = NULLIF($4, ''), updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1
 		RETURNING updated_at`
 
@@ -66,13 +68,13 @@ func (r *UserRepo) UpdateUser(user *model.User) error {
 		user.UserName,
 	)
 
-	return result.Scan(&user.UpdatedAt)
+	return result.Scan(&user.UpdatedAt.UpdatedAt)
 }
 
 func (r *UserRepo) GetUserByID(id int64) (*model.User, error) {
 	user := &model.User{}
 	query := `
-		SELECT id, first_name, last_name, username, updated_at
+		SELECT id, first_name, COALESCE(last_name, '') AS last_name, COALESCE(username, '') AS username, updated_at
 		FROM users
 		WHERE id = $1`
 
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"nstu/internal/model"
+	"testing"
+	"time"
+)
+
+func TestUserRepoWrites(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		write func(r *UserRepo, user *model.User) error
+	}{
+		{
+			name:  "create",
+			query: "INSERT INTO users (id, first_name, last_name, username) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) RETURNING updated_at",
+			write: (*UserRepo).CreateUser,
+		},
+		{
+			name:  "create if not exists",
+			query: "ON CONFLICT (id) DO UPDATE",
+			write: (*UserRepo).CreateUserIfNotExists,
+		},
+		{
+			name:  "update",
+			query: "UPDATE users SET first_name = $2, last_name = NULLIF($3, ''), username = NULLIF($4, '')",
+			write: (*UserRepo).UpdateUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := &fakeCall{
+				query:   tt.query,
+				columns: []string{"updated_at"},
+				rows:    [][]driver.Value{{updatedAt}},
+			}
+			db, _ := newFakeDB(t, call)
+
+			user := &model.User{ID: 42, FirstName: "Ivan", LastName: "Petrov", UserName: "ivan"}
+			if err := tt.write(NewUserRepo(db), user); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+
+			want := []driver.Value{int64(42), "Ivan", "Petrov", "ivan"}
+			assertArgs(t, call.args, want)
+			if !user.UpdatedAt.UpdatedAt.Equal(updatedAt) {
+				t.Errorf("updated_at = %v, want %v", user.UpdatedAt.UpdatedAt, updatedAt)
+			}
+		})
+	}
+}
+
+// Пользователи без username не конфликтуют по уникальному users.username:
+// пустые значения сохраняются как NULL и при вставке, и при обновлении
+func TestCreateUsersWithoutUsername(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	users := []*model.User{
+		{ID: 42, FirstName: "Ivan"},
+		{ID: 43, FirstName: "Petr"},
+	}
+
+	var calls []*fakeCall
+	for range users {
+		calls = append(calls, &fakeCall{
+			query:   "VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) ON CONFLICT (id) DO UPDATE SET first_name = $2, last_name = NULLIF($3, ''), username = NULLIF($4, '')",
+			columns: []string{"updated_at"},
+			rows:    [][]driver.Value{{updatedAt}},
+		})
+	}
+	db, _ := newFakeDB(t, calls...)
+	repo := NewUserRepo(db)
+
+	for i, user := range users {
+		if err := repo.CreateUserIfNotExists(user); err != nil {
+			t.Fatalf("create user %d failed: %v", user.ID, err)
+		}
+		assertArgs(t, calls[i].args, []driver.Value{user.ID, user.FirstName, "", ""})
+	}
+}
+
+func TestGetUserWithoutUsername(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	call := &fakeCall{
+		query:   "COALESCE(last_name, '') AS last_name, COALESCE(username, '') AS username",
+		columns: []string{"id", "first_name", "last_name", "username", "updated_at"},
+		rows:    [][]driver.Value{{int64(42), "Ivan", "", "", updatedAt}},
+	}
+	db, _ := newFakeDB(t, call)
+
+	user, err := NewUserRepo(db).GetUserByID(42)
+	if err != nil {
+		t.Fatalf("GetUserByID failed: %v", err)
+	}
+	if user.ID != 42 || user.FirstName != "Ivan" || user.LastName != "" || user.UserName != "" {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+// assertArgs сравнивает аргументы запроса с ожидаемыми
+func assertArgs(t *testing.T, got, want []driver.Value) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("args = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("arg $%d = %#v, want %#v", i+1, got[i], want[i])
+		}
+	}
+}
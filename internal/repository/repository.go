@@ -2,12 +2,23 @@
 package repository
 
 import (
-	"pet1/internal/model"
+	"errors"
+	"nstu/internal/model"
+)
+
+var (
+	// ErrNotFound возвращается, если запись не найдена
+	ErrNotFound = errors.New("record not found")
+
+	// ErrConflict возвращается, если запись была изменена параллельно
+	ErrConflict = errors.New("record was changed concurrently")
 )
 
 type Repository interface {
 	User
 	Form
+	Notification
+	Message
 }
 
 type User interface {
@@ -23,4 +34,21 @@ type Form interface {
 	UpdateForm(form *model.Form) error
 	DeleteForm(id int64) error
 	ListForms(offset, limit int, userID int64) ([]model.Form, error)
+	ListFormsByStatus(status model.FormStatus, offset, limit int) ([]model.Form, error)
+	UpdateFormStatus(change *model.FormStatusChange) error
+	GetFormStatusHistory(formID int64) ([]model.FormStatusChange, error)
+	UpdateFormStatusAndAssignee(change *model.FormStatusChange, assigneeID int64) error
+}
+
+type Notification interface {
+	CreateFormNotification(notification *model.FormNotification) error
+	ListFormNotifications(formID int64) ([]model.FormNotification, error)
+	GetFormNotification(formID, chatID int64) (*model.FormNotification, error)
+}
+
+type Message interface {
+	CreateFormMessage(message *model.FormMessage) error
+	GetFormMessageByUserMessage(chatID int64, messageID int) (*model.FormMessage, error)
+	GetFormIDByAdminMessage(chatID int64, messageID int) (int64, error)
+	ListFormMessages(formID int64) ([]model.FormMessage, error)
 }
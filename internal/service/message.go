@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+)
+
+// FindFormByAdminMessage возвращает ID заявки по сообщению в чате администраторов
+func (srv *Service) FindFormByAdminMessage(chatID int64, messageID int) (int64, error) {
+	formID, err := srv.repo.GetFormIDByAdminMessage(chatID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, ErrFormNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find form by admin message: %w", err)
+	}
+	return formID, nil
+}
+
+// FindFormMessageByUserMessage возвращает сообщение переписки по сообщению в личном чате пользователя
+func (srv *Service) FindFormMessageByUserMessage(chatID int64, messageID int) (*model.FormMessage, error) {
+	message, err := srv.repo.GetFormMessageByUserMessage(chatID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find form message: %w", err)
+	}
+	return message, nil
+}
+
+// GetFormNotification возвращает уведомление о заявке в чате администраторов
+func (srv *Service) GetFormNotification(formID, chatID int64) (*model.FormNotification, error) {
+	notification, err := srv.repo.GetFormNotification(formID, chatID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form notification: %w", err)
+	}
+	return notification, nil
+}
+
+// AddOperatorMessage сохраняет сообщение оператора пользователю
+// и переводит заявку в ожидание ответа пользователя
+func (srv *Service) AddOperatorMessage(message *model.FormMessage) error {
+	message.Direction = model.FormMessageFromOperator
+	if err := srv.repo.CreateFormMessage(message); err != nil {
+		return fmt.Errorf("failed to save operator message: %w", err)
+	}
+
+	form, err := srv.repo.GetFormByID(message.FormID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrFormNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get form: %w", err)
+	}
+	if form.Status == model.FormStatusWaitingForUser {
+		return nil
+	}
+	return srv.changeStatus(form, model.FormStatusWaitingForUser, message.AuthorID, nil)
+}
+
+// AddUserMessage сохраняет ответ пользователя оператору.
+// Заявка, ожидавшая ответа пользователя, возвращается в работу.
+func (srv *Service) AddUserMessage(message *model.FormMessage) error {
+	message.Direction = model.FormMessageFromUser
+	if err := srv.repo.CreateFormMessage(message); err != nil {
+		return fmt.Errorf("failed to save user message: %w", err)
+	}
+
+	form, err := srv.GetUserForm(message.AuthorID, message.FormID)
+	if err != nil {
+		return err
+	}
+	if form.Status != model.FormStatusWaitingForUser {
+		return nil
+	}
+	return srv.changeStatus(form, model.FormStatusInProgress, message.AuthorID, nil)
+}
+
+// ListFormMessages возвращает переписку по заявке
+func (srv *Service) ListFormMessages(formID int64) ([]model.FormMessage, error) {
+	messages, err := srv.repo.ListFormMessages(formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list form messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"nstu/internal/model"
+	"testing"
+)
+
+func TestAddOperatorMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     model.FormStatus
+		wantStatus model.FormStatus
+		wantChange bool
+	}{
+		{name: "new form", status: model.FormStatusNew, wantStatus: model.FormStatusWaitingForUser, wantChange: true},
+		{name: "in progress", status: model.FormStatusInProgress, wantStatus: model.FormStatusWaitingForUser, wantChange: true},
+		{name: "already waiting", status: model.FormStatusWaitingForUser, wantStatus: model.FormStatusWaitingForUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(newForm(1, 42, tt.status))
+			message := &model.FormMessage{FormID: 1, AuthorID: 100, Text: "Уточните вопрос"}
+			if err := NewService(repo).AddOperatorMessage(message); err != nil {
+				t.Fatalf("AddOperatorMessage failed: %v", err)
+			}
+
+			if len(repo.messages) != 1 || repo.messages[0].Direction != model.FormMessageFromOperator {
+				t.Errorf("messages = %+v", repo.messages)
+			}
+			if repo.forms[1].Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", repo.forms[1].Status, tt.wantStatus)
+			}
+			if got := len(repo.changes) == 1; got != tt.wantChange {
+				t.Errorf("history = %+v, want change %v", repo.changes, tt.wantChange)
+			}
+			if tt.wantChange && repo.changes[0].ChangedBy != 100 {
+				t.Errorf("changed by %d, want operator", repo.changes[0].ChangedBy)
+			}
+		})
+	}
+}
+
+func TestAddUserMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     model.FormStatus
+		authorID   int64
+		wantStatus model.FormStatus
+		wantErr    error
+	}{
+		{name: "answer to operator", status: model.FormStatusWaitingForUser, authorID: 42, wantStatus: model.FormStatusInProgress},
+		{name: "extra message", status: model.FormStatusInProgress, authorID: 42, wantStatus: model.FormStatusInProgress},
+		{name: "other user", status: model.FormStatusWaitingForUser, authorID: 43, wantStatus: model.FormStatusWaitingForUser, wantErr: ErrFormNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(newForm(1, 42, tt.status))
+			message := &model.FormMessage{FormID: 1, AuthorID: tt.authorID, Text: "Вопрос про общежитие"}
+			err := NewService(repo).AddUserMessage(message)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddUserMessage error = %v, want %v", err, tt.wantErr)
+			}
+
+			if message.Direction != model.FormMessageFromUser {
+				t.Errorf("direction = %s", message.Direction)
+			}
+			if repo.forms[1].Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", repo.forms[1].Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestFindFormByAdminMessage(t *testing.T) {
+	repo := newStubRepo()
+	repo.adminMessages[[2]int64{-100100, 15}] = 7
+	srv := NewService(repo)
+
+	if formID, err := srv.FindFormByAdminMessage(-100100, 15); err != nil || formID != 7 {
+		t.Errorf("FindFormByAdminMessage = %d, %v", formID, err)
+	}
+	if _, err := srv.FindFormByAdminMessage(-100100, 16); !errors.Is(err, ErrFormNotFound) {
+		t.Errorf("unknown message error = %v, want ErrFormNotFound", err)
+	}
+}
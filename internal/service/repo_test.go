@@ -0,0 +1,144 @@
+package service
+
+import (
+	"nstu/internal/model"
+	"nstu/internal/repository"
+)
+
+// stubRepo репозиторий в памяти для тестов сервиса. Методы, которые тест не использует,
+// паникуют через встроенный nil repository.Repository.
+type stubRepo struct {
+	repository.Repository
+
+	forms         map[int64]*model.Form
+	users         map[int64]model.User
+	changes       []model.FormStatusChange
+	messages      []model.FormMessage
+	adminMessages map[[2]int64]int64 // ID заявки по чату и сообщению администраторов
+
+	createUserErr   error // Ошибка CreateUserIfNotExists
+	createFormErr   error // Ошибка CreateForm
+	updateFormErr   error // Ошибка UpdateForm, например закрытие заявки оператором после проверки статуса
+	updateStatusErr error // Ошибка UpdateFormStatus и UpdateFormStatusAndAssignee
+	assignErr       error // Ошибка назначения в UpdateFormStatusAndAssignee
+}
+
+func newStubRepo(forms ...model.Form) *stubRepo {
+	r := &stubRepo{
+		forms:         make(map[int64]*model.Form),
+		users:         make(map[int64]model.User),
+		adminMessages: make(map[[2]int64]int64),
+	}
+	for i := range forms {
+		form := forms[i]
+		r.forms[form.ID.ID] = &form
+	}
+	return r
+}
+
+func (r *stubRepo) GetFormByID(id int64) (*model.Form, error) {
+	form, ok := r.forms[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *form
+	return &copied, nil
+}
+
+func (r *stubRepo) ListForms(offset, limit int, userID int64) ([]model.Form, error) {
+	var forms []model.Form
+	for id := int64(1); id <= int64(len(r.forms)); id++ {
+		if form, ok := r.forms[id]; ok && (userID == 0 || form.UserID == userID) {
+			forms = append(forms, *form)
+		}
+	}
+	if offset >= len(forms) {
+		return nil, nil
+	}
+	return forms[offset:min(offset+limit, len(forms))], nil
+}
+
+func (r *stubRepo) UpdateForm(form *model.Form) error {
+	if r.updateFormErr != nil {
+		return r.updateFormErr
+	}
+	stored, ok := r.forms[form.ID.ID]
+	if !ok || stored.UserID != form.UserID || !stored.Status.Open() {
+		return repository.ErrConflict
+	}
+	*stored = *form
+	return nil
+}
+
+func (r *stubRepo) UpdateFormStatus(change *model.FormStatusChange) error {
+	if r.updateStatusErr != nil {
+		return r.updateStatusErr
+	}
+	form, ok := r.forms[change.FormID]
+	if !ok || form.Status != change.FromStatus {
+		return repository.ErrConflict
+	}
+	form.Status = change.ToStatus
+	change.ID = int64(len(r.changes) + 1)
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+func (r *stubRepo) GetUserByID(id int64) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *stubRepo) CreateUserIfNotExists(user *model.User) error {
+	if r.createUserErr != nil {
+		return r.createUserErr
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *stubRepo) CreateForm(form *model.Form) error {
+	if r.createFormErr != nil {
+		return r.createFormErr
+	}
+	form.ID.ID = int64(len(r.forms) + 1)
+	copied := *form
+	r.forms[form.ID.ID] = &copied
+	return nil
+}
+
+// UpdateFormStatusAndAssignee как транзакция: при ошибке назначения статус и история не меняются
+func (r *stubRepo) UpdateFormStatusAndAssignee(change *model.FormStatusChange, assigneeID int64) error {
+	if r.assignErr != nil {
+		return r.assignErr
+	}
+	if err := r.UpdateFormStatus(change); err != nil {
+		return err
+	}
+	r.forms[change.FormID].Assignee = &assigneeID
+	return nil
+}
+
+func (r *stubRepo) CreateFormMessage(message *model.FormMessage) error {
+	message.ID = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, *message)
+	return nil
+}
+
+func (r *stubRepo) GetFormIDByAdminMessage(chatID int64, messageID int) (int64, error) {
+	formID, ok := r.adminMessages[[2]int64{chatID, int64(messageID)}]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	return formID, nil
+}
+
+// newForm создает заявку пользователя userID в статусе status
+func newForm(id, userID int64, status model.FormStatus) model.Form {
+	form := model.Form{UserID: userID, Name: "Ivan", Status: status}
+	form.ID.ID = id
+	return form
+}
@@ -1,38 +1,220 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"nstu/internal/logger"
 	"nstu/internal/model"
 	"nstu/internal/repository"
+)
+
+// formChanSize размер буфера канала уведомлений о новых заявках
+const formChanSize = 100
 
-	initdata "github.com/telegram-mini-apps/init-data-golang"
+var (
+	// ErrFormNotFound заявка не найдена или принадлежит другому пользователю
+	ErrFormNotFound = errors.New("form not found")
 )
 
 // Servicer интерфейс для работы с бизнес логикой
 type Servicer interface {
-	CreateForm(userID model.User, form *model.Request) error
+	CreateForm(user *model.User, form *model.Form) error
+	ListUserForms(userID int64, offset, limit int) ([]model.Form, error)
+	GetUserForm(userID, formID int64) (*model.Form, error)
+	UpdateUserForm(userID int64, form *model.Form) error
+	WithdrawUserForm(userID, formID int64) error
+	ChangeFormStatus(formID int64, to model.FormStatus, changedBy int64) (*model.Form, error)
+	ListFormsByStatus(status model.FormStatus, offset, limit int) ([]model.Form, error)
+	GetFormStatusHistory(formID int64) ([]model.FormStatusChange, error)
+	TakeForm(formID int64, operator *model.User) (*model.Form, error)
+	GetFormRequest(formID int64) (*model.Request, error)
+	AddFormNotification(notification *model.FormNotification) error
+	ListFormNotifications(formID int64) ([]model.FormNotification, error)
+	GetFormNotification(formID, chatID int64) (*model.FormNotification, error)
+	FindFormByAdminMessage(chatID int64, messageID int) (int64, error)
+	FindFormMessageByUserMessage(chatID int64, messageID int) (*model.FormMessage, error)
+	AddOperatorMessage(message *model.FormMessage) error
+	AddUserMessage(message *model.FormMessage) error
+	ListFormMessages(formID int64) ([]model.FormMessage, error)
 	GetMessageChan() chan *model.Request
+	GetFormChangeChan() chan int64
 }
 
 // Service содержит бизнес-логику приложения
 type Service struct {
-	repo repository.Repository // репозиторий для работы с базой данных
-	ch   chan *model.Request   // канал для отправки сообщений о новых заявках
+	repo    repository.Repository // репозиторий для работы с базой данных
+	ch      chan *model.Request   // канал для отправки сообщений о новых заявках
+	changes chan int64            // канал ID заявок, измененных пользователем, для обновления уведомлений
 }
 
 func NewService(repo repository.Repository) *Service {
 	return &Service{
-		repo: repo,
+		repo:    repo,
+		ch:      make(chan *model.Request, formChanSize),
+		changes: make(chan int64, formChanSize),
+	}
+}
+
+// CreateForm сохраняет пользователя и его заявку, после чего отправляет
+// заявку в канал уведомлений бота. В form заполняются ID и время создания.
+func (srv *Service) CreateForm(user *model.User, form *model.Form) error {
+	if err := srv.repo.CreateUserIfNotExists(user); err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	form.UserID = user.ID
+	form.Status = model.FormStatusNew
+	if err := srv.repo.CreateForm(form); err != nil {
+		return fmt.Errorf("failed to save form: %w", err)
 	}
+
+	srv.notify(&model.Request{Form: *form, User: *user})
+	return nil
 }
-func (srv *Service) Auth(initData initdata.InitData) (model.User, error) {
-	return model.User{}, nil
+
+// notify отправляет заявку в канал уведомлений, не блокируя запрос.
+// Заявка уже сохранена, поэтому при переполнении буфера уведомление пропускается.
+func (srv *Service) notify(request *model.Request) {
+	select {
+	case srv.ch <- request:
+	default:
+		logger.Log.Warn().
+			Int64("form_id", request.Form.ID.ID).
+			Msg("Канал уведомлений переполнен, уведомление о заявке пропущено")
+	}
+}
+
+// notifyChanged отправляет ID измененной заявки в канал обновления уведомлений, не блокируя запрос.
+// При переполнении буфера уведомления остаются в прежнем виде до следующего изменения.
+func (srv *Service) notifyChanged(formID int64) {
+	select {
+	case srv.changes <- formID:
+	default:
+		logger.Log.Warn().
+			Int64("form_id", formID).
+			Msg("Канал изменений заявок переполнен, уведомления о заявке не обновлены")
+	}
 }
 
-func (srv *Service) CreateForm(user model.User, form model.Form) error {
+// ListUserForms возвращает заявки пользователя, начиная с последних
+func (srv *Service) ListUserForms(userID int64, offset, limit int) ([]model.Form, error) {
+	forms, err := srv.repo.ListForms(offset, limit, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user forms: %w", err)
+	}
+	return forms, nil
+}
+
+// GetUserForm возвращает заявку, если она принадлежит пользователю.
+// Для чужих и несуществующих заявок возвращается ErrFormNotFound.
+func (srv *Service) GetUserForm(userID, formID int64) (*model.Form, error) {
+	form, err := srv.repo.GetFormByID(formID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form: %w", err)
+	}
+	if form.UserID != userID {
+		return nil, ErrFormNotFound
+	}
+	return form, nil
+}
+
+// UpdateUserForm изменяет поля заявки пользователя, пока работа по ней не завершена.
+// В form должен быть заполнен ID, остальные служебные поля берутся из БД.
+func (srv *Service) UpdateUserForm(userID int64, form *model.Form) error {
+	current, err := srv.GetUserForm(userID, form.ID.ID)
+	if err != nil {
+		return err
+	}
+	if !current.Status.Open() {
+		return ErrFormClosed
+	}
+
+	current.Name = form.Name
+	current.Feedback = form.Feedback
+	current.Comment = form.Comment
+	err = srv.repo.UpdateForm(current)
+	// Оператор закрыл заявку после проверки статуса
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrFormClosed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update form: %w", err)
+	}
 
-	srv.ch <- request
+	*form = *current
+	srv.notifyChanged(form.ID.ID)
 	return nil
 }
+
+// WithdrawUserForm отзывает заявку пользователя, пока работа по ней не завершена
+func (srv *Service) WithdrawUserForm(userID, formID int64) error {
+	form, err := srv.GetUserForm(userID, formID)
+	if err != nil {
+		return err
+	}
+	if !form.Status.Open() {
+		return ErrFormClosed
+	}
+
+	if err := srv.changeStatus(form, model.FormStatusWithdrawn, userID, nil); err != nil {
+		return err
+	}
+	srv.notifyChanged(formID)
+	return nil
+}
+
+// GetFormRequest возвращает заявку вместе с автором и назначенным оператором
+func (srv *Service) GetFormRequest(formID int64) (*model.Request, error) {
+	form, err := srv.repo.GetFormByID(formID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form: %w", err)
+	}
+
+	user, err := srv.repo.GetUserByID(form.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form author: %w", err)
+	}
+
+	request := &model.Request{Form: *form, User: *user}
+	if form.Assignee != nil {
+		assignee, err := srv.repo.GetUserByID(*form.Assignee)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get form assignee: %w", err)
+		}
+		request.Assignee = assignee
+	}
+
+	return request, nil
+}
+
+// AddFormNotification сохраняет уведомление о заявке, отправленное в чат администраторов
+func (srv *Service) AddFormNotification(notification *model.FormNotification) error {
+	if err := srv.repo.CreateFormNotification(notification); err != nil {
+		return fmt.Errorf("failed to save form notification: %w", err)
+	}
+	return nil
+}
+
+// ListFormNotifications возвращает все уведомления о заявке
+func (srv *Service) ListFormNotifications(formID int64) ([]model.FormNotification, error) {
+	notifications, err := srv.repo.ListFormNotifications(formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list form notifications: %w", err)
+	}
+	return notifications, nil
+}
+
 func (srv *Service) GetMessageChan() chan *model.Request {
 	return srv.ch
 }
+
+// GetFormChangeChan возвращает канал ID заявок, измененных или отозванных пользователем
+func (srv *Service) GetFormChangeChan() chan int64 {
+	return srv.changes
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestListUserForms(t *testing.T) {
+	repo := newStubRepo(
+		newForm(1, 42, model.FormStatusNew),
+		newForm(2, 43, model.FormStatusNew),
+		newForm(3, 42, model.FormStatusResolved),
+	)
+
+	tests := []struct {
+		name          string
+		userID        int64
+		offset, limit int
+		want          []int64
+	}{
+		{name: "own forms", userID: 42, limit: 10, want: []int64{1, 3}},
+		{name: "page", userID: 42, offset: 1, limit: 1, want: []int64{3}},
+		{name: "past the end", userID: 42, offset: 5, limit: 10, want: nil},
+		{name: "without forms", userID: 44, limit: 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, err := NewService(repo).ListUserForms(tt.userID, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatalf("ListUserForms failed: %v", err)
+			}
+			var ids []int64
+			for _, form := range forms {
+				ids = append(ids, form.ID.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("forms = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("forms = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetUserForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int64
+		formID  int64
+		wantErr error
+	}{
+		{name: "own form", userID: 42, formID: 1},
+		{name: "other user form", userID: 43, formID: 1, wantErr: ErrFormNotFound},
+		{name: "missing form", userID: 42, formID: 2, wantErr: ErrFormNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(newForm(1, 42, model.FormStatusNew))
+			form, err := NewService(repo).GetUserForm(tt.userID, tt.formID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUserForm error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && form.ID.ID != tt.formID {
+				t.Errorf("form id = %d, want %d", form.ID.ID, tt.formID)
+			}
+		})
+	}
+}
+
+func TestUpdateUserForm(t *testing.T) {
+	tests := []struct {
+		name      string
+		form      model.Form
+		userID    int64
+		updateErr error
+		wantErr   error
+	}{
+		{name: "new form", form: newForm(1, 42, model.FormStatusNew), userID: 42},
+		{name: "waiting form", form: newForm(1, 42, model.FormStatusWaitingForUser), userID: 42},
+		{name: "other user", form: newForm(1, 42, model.FormStatusNew), userID: 43, wantErr: ErrFormNotFound},
+		{name: "resolved form", form: newForm(1, 42, model.FormStatusResolved), userID: 42, wantErr: ErrFormClosed},
+		{name: "withdrawn form", form: newForm(1, 42, model.FormStatusWithdrawn), userID: 42, wantErr: ErrFormClosed},
+		{
+			name:      "closed after check",
+			form:      newForm(1, 42, model.FormStatusInProgress),
+			userID:    42,
+			updateErr: repository.ErrConflict,
+			wantErr:   ErrFormClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(tt.form)
+			repo.updateFormErr = tt.updateErr
+			srv := NewService(repo)
+
+			// Клиент передает только редактируемые поля, служебные берутся из БД
+			update := &model.Form{Name: "Petr", Feedback: "@petr", Comment: "Новый вопрос", Status: model.FormStatusResolved}
+			update.ID.ID = 1
+			err := srv.UpdateUserForm(tt.userID, update)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUserForm error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored := repo.forms[1]
+			if tt.wantErr != nil {
+				if stored.Name != tt.form.Name {
+					t.Errorf("failed update changed name to %q", stored.Name)
+				}
+				if len(srv.GetFormChangeChan()) != 0 {
+					t.Error("failed update refreshed notifications")
+				}
+				return
+			}
+			if formID := <-srv.GetFormChangeChan(); formID != 1 {
+				t.Errorf("changed form id = %d, want 1", formID)
+			}
+			if stored.Name != "Petr" || stored.Feedback != "@petr" || stored.Comment != "Новый вопрос" {
+				t.Errorf("stored form = %+v", stored)
+			}
+			if stored.Status != tt.form.Status || stored.UserID != 42 || update.Status != tt.form.Status {
+				t.Errorf("service fields changed: status %s, user %d", stored.Status, stored.UserID)
+			}
+		})
+	}
+}
+
+func TestGetFormRequest(t *testing.T) {
+	assignee := int64(100)
+	assigned := newForm(1, 42, model.FormStatusInProgress)
+	assigned.Assignee = &assignee
+
+	tests := []struct {
+		name         string
+		form         model.Form
+		formID       int64
+		wantAssignee bool
+		wantErr      error
+	}{
+		{name: "new form", form: newForm(1, 42, model.FormStatusNew), formID: 1},
+		{name: "assigned form", form: assigned, formID: 1, wantAssignee: true},
+		{name: "missing form", form: newForm(1, 42, model.FormStatusNew), formID: 2, wantErr: ErrFormNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(tt.form)
+			repo.users[42] = model.User{ID: 42, FirstName: "Ivan"}
+			repo.users[100] = model.User{ID: 100, FirstName: "Operator"}
+
+			request, err := NewService(repo).GetFormRequest(tt.formID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFormRequest error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if request.Form.ID.ID != 1 || request.User.ID != 42 {
+				t.Errorf("request form %d, user %d", request.Form.ID.ID, request.User.ID)
+			}
+			if (request.Assignee != nil) != tt.wantAssignee || (tt.wantAssignee && request.Assignee.ID != 100) {
+				t.Errorf("assignee = %+v, want %v", request.Assignee, tt.wantAssignee)
+			}
+		})
+	}
+}
+
+func TestCreateForm(t *testing.T) {
+	errDB := errors.New("db is down")
+
+	tests := []struct {
+		name          string
+		createUserErr error
+		createFormErr error
+		wantErr       error
+		wantNotified  bool
+	}{
+		{name: "created", wantNotified: true},
+		{name: "user not saved", createUserErr: errDB, wantErr: errDB},
+		{name: "form not saved", createFormErr: errDB, wantErr: errDB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo()
+			repo.createUserErr, repo.createFormErr = tt.createUserErr, tt.createFormErr
+			srv := NewService(repo)
+
+			user := &model.User{ID: 42, FirstName: "Ivan"}
+			// Статус из запроса не сохраняется: новая заявка всегда в статусе new
+			form := &model.Form{Name: "Ivan", Comment: "Вопрос", Status: model.FormStatusResolved}
+			err := srv.CreateForm(user, form)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateForm() error = %v, want %v", err, tt.wantErr)
+			}
+
+			select {
+			case request := <-srv.GetMessageChan():
+				if !tt.wantNotified {
+					t.Fatalf("unexpected notification for form %d", request.Form.ID.ID)
+				}
+				if request.Form.ID.ID != form.ID.ID || request.User.ID != 42 {
+					t.Errorf("notification = form %d, user %d", request.Form.ID.ID, request.User.ID)
+				}
+			default:
+				if tt.wantNotified {
+					t.Fatal("form was not sent to the bot")
+				}
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored := repo.forms[form.ID.ID]
+			if stored == nil || stored.UserID != 42 || stored.Status != model.FormStatusNew {
+				t.Errorf("stored form = %+v", stored)
+			}
+			if _, ok := repo.users[42]; !ok {
+				t.Error("user was not saved")
+			}
+		})
+	}
+}
+
+func TestCreateFormFullChannel(t *testing.T) {
+	srv := NewService(newStubRepo())
+	for i := 0; i < formChanSize; i++ {
+		srv.ch <- &model.Request{}
+	}
+
+	// Заявка сохраняется, даже если бот не успевает разбирать уведомления
+	done := make(chan error, 1)
+	go func() { done <- srv.CreateForm(&model.User{ID: 42}, &model.Form{Name: "Ivan"}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("CreateForm() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CreateForm blocked on a full notification channel")
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+)
+
+var (
+	// ErrInvalidStatus неизвестный статус заявки
+	ErrInvalidStatus = errors.New("invalid form status")
+
+	// ErrInvalidTransition переход между статусами запрещен
+	ErrInvalidTransition = errors.New("form status transition is not allowed")
+
+	// ErrFormClosed работа по заявке завершена, изменять ее нельзя
+	ErrFormClosed = errors.New("form is closed")
+
+	// ErrStatusConflict статус заявки был изменен параллельно
+	ErrStatusConflict = errors.New("form status was changed concurrently")
+)
+
+// transitions разрешенные переходы между статусами заявки.
+// Статусы resolved, rejected и withdrawn конечные.
+var transitions = map[model.FormStatus][]model.FormStatus{
+	model.FormStatusNew: {
+		model.FormStatusInProgress,
+		model.FormStatusWaitingForUser,
+		model.FormStatusResolved,
+		model.FormStatusRejected,
+		model.FormStatusWithdrawn,
+	},
+	model.FormStatusInProgress: {
+		model.FormStatusWaitingForUser,
+		model.FormStatusResolved,
+		model.FormStatusRejected,
+		model.FormStatusWithdrawn,
+	},
+	model.FormStatusWaitingForUser: {
+		model.FormStatusInProgress,
+		model.FormStatusResolved,
+		model.FormStatusRejected,
+		model.FormStatusWithdrawn,
+	},
+}
+
+// CanTransition проверяет, разрешен ли переход из статуса from в статус to
+func CanTransition(from, to model.FormStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeFormStatus переводит заявку в новый статус с проверкой правил перехода.
+// changedBy - ID пользователя Telegram, изменившего статус.
+func (srv *Service) ChangeFormStatus(formID int64, to model.FormStatus, changedBy int64) (*model.Form, error) {
+	if !to.Valid() {
+		return nil, ErrInvalidStatus
+	}
+
+	form, err := srv.repo.GetFormByID(formID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form: %w", err)
+	}
+
+	if err := srv.changeStatus(form, to, changedBy, nil); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// changeStatus переводит загруженную заявку в новый статус.
+// Если задан assigneeID, в той же транзакции заявка назначается оператору.
+func (srv *Service) changeStatus(form *model.Form, to model.FormStatus, changedBy int64, assigneeID *int64) error {
+	if !CanTransition(form.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, form.Status, to)
+	}
+
+	change := &model.FormStatusChange{
+		FormID:     form.ID.ID,
+		FromStatus: form.Status,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+	}
+	var err error
+	if assigneeID != nil {
+		err = srv.repo.UpdateFormStatusAndAssignee(change, *assigneeID)
+	} else {
+		err = srv.repo.UpdateFormStatus(change)
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrStatusConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change form status: %w", err)
+	}
+
+	form.Status = to
+	form.UpdatedAt.UpdatedAt = change.ChangedAt
+	if assigneeID != nil {
+		form.Assignee = assigneeID
+	}
+	return nil
+}
+
+// TakeForm назначает заявку оператору и переводит ее в работу.
+// Статус, запись истории и назначение сохраняются в одной транзакции.
+func (srv *Service) TakeForm(formID int64, operator *model.User) (*model.Form, error) {
+	if err := srv.repo.CreateUserIfNotExists(operator); err != nil {
+		return nil, fmt.Errorf("failed to save operator: %w", err)
+	}
+
+	form, err := srv.repo.GetFormByID(formID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form: %w", err)
+	}
+
+	operatorID := operator.ID
+	if err := srv.changeStatus(form, model.FormStatusInProgress, operator.ID, &operatorID); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// ListFormsByStatus возвращает заявки с указанным статусом, начиная с самых старых
+func (srv *Service) ListFormsByStatus(status model.FormStatus, offset, limit int) ([]model.Form, error) {
+	if !status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+
+	forms, err := srv.repo.ListFormsByStatus(status, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list forms by status: %w", err)
+	}
+	return forms, nil
+}
+
+// GetFormStatusHistory возвращает историю изменения статусов заявки
+func (srv *Service) GetFormStatusHistory(formID int64) ([]model.FormStatusChange, error) {
+	history, err := srv.repo.GetFormStatusHistory(formID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form status history: %w", err)
+	}
+	return history, nil
+}
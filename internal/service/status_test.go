@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"nstu/internal/model"
+	"nstu/internal/repository"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[model.FormStatus][]model.FormStatus{
+		model.FormStatusNew:            {model.FormStatusInProgress, model.FormStatusWaitingForUser, model.FormStatusResolved, model.FormStatusRejected, model.FormStatusWithdrawn},
+		model.FormStatusInProgress:     {model.FormStatusWaitingForUser, model.FormStatusResolved, model.FormStatusRejected, model.FormStatusWithdrawn},
+		model.FormStatusWaitingForUser: {model.FormStatusInProgress, model.FormStatusResolved, model.FormStatusRejected, model.FormStatusWithdrawn},
+	}
+
+	// Проверяются все пары статусов: разрешены только переходы из таблицы
+	for _, from := range model.FormStatuses {
+		for _, to := range model.FormStatuses {
+			want := false
+			for _, status := range allowed[from] {
+				if status == to {
+					want = true
+				}
+			}
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestFormStatusOpen(t *testing.T) {
+	for _, status := range model.FormStatuses {
+		_, hasTransitions := transitions[status]
+		if status.Open() != hasTransitions {
+			t.Errorf("%s: Open() = %v, but has transitions = %v", status, status.Open(), hasTransitions)
+		}
+	}
+}
+
+func TestChangeFormStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    model.Form
+		formID  int64
+		to      model.FormStatus
+		repoErr error
+		wantErr error
+	}{
+		{name: "take new", form: newForm(1, 42, model.FormStatusNew), formID: 1, to: model.FormStatusInProgress},
+		{name: "resolve waiting", form: newForm(1, 42, model.FormStatusWaitingForUser), formID: 1, to: model.FormStatusResolved},
+		{name: "unknown status", form: newForm(1, 42, model.FormStatusNew), formID: 1, to: "closed", wantErr: ErrInvalidStatus},
+		{name: "not found", form: newForm(1, 42, model.FormStatusNew), formID: 2, to: model.FormStatusResolved, wantErr: ErrFormNotFound},
+		{name: "from final status", form: newForm(1, 42, model.FormStatusResolved), formID: 1, to: model.FormStatusInProgress, wantErr: ErrInvalidTransition},
+		{name: "same status", form: newForm(1, 42, model.FormStatusInProgress), formID: 1, to: model.FormStatusInProgress, wantErr: ErrInvalidTransition},
+		{name: "concurrent change", form: newForm(1, 42, model.FormStatusNew), formID: 1, to: model.FormStatusResolved, repoErr: repository.ErrConflict, wantErr: ErrStatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(tt.form)
+			repo.updateStatusErr = tt.repoErr
+			srv := NewService(repo)
+
+			form, err := srv.ChangeFormStatus(tt.formID, tt.to, 100)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangeFormStatus error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.changes) != 0 {
+					t.Errorf("history has %d rows after failed change", len(repo.changes))
+				}
+				return
+			}
+
+			if form.Status != tt.to {
+				t.Errorf("status = %s, want %s", form.Status, tt.to)
+			}
+			want := model.FormStatusChange{ID: 1, FormID: 1, FromStatus: tt.form.Status, ToStatus: tt.to, ChangedBy: 100}
+			if len(repo.changes) != 1 || repo.changes[0] != want {
+				t.Errorf("history = %+v, want %+v", repo.changes, want)
+			}
+		})
+	}
+}
+
+func TestWithdrawUserForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    model.Form
+		userID  int64
+		wantErr error
+	}{
+		{name: "own open form", form: newForm(1, 42, model.FormStatusInProgress), userID: 42},
+		{name: "other user", form: newForm(1, 42, model.FormStatusNew), userID: 43, wantErr: ErrFormNotFound},
+		{name: "closed form", form: newForm(1, 42, model.FormStatusRejected), userID: 42, wantErr: ErrFormClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(tt.form)
+			srv := NewService(repo)
+			err := srv.WithdrawUserForm(tt.userID, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithdrawUserForm error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(srv.GetFormChangeChan()) != 0 {
+					t.Error("failed withdraw refreshed notifications")
+				}
+				return
+			}
+			if repo.forms[1].Status != model.FormStatusWithdrawn {
+				t.Errorf("status = %s, want withdrawn", repo.forms[1].Status)
+			}
+			if formID := <-srv.GetFormChangeChan(); formID != 1 {
+				t.Errorf("changed form id = %d, want 1", formID)
+			}
+		})
+	}
+}
+
+func TestTakeForm(t *testing.T) {
+	tests := []struct {
+		name      string
+		form      model.Form
+		assignErr error
+		wantErr   error
+	}{
+		{name: "new form", form: newForm(1, 42, model.FormStatusNew)},
+		{name: "waiting form", form: newForm(1, 42, model.FormStatusWaitingForUser)},
+		{name: "already taken", form: newForm(1, 42, model.FormStatusInProgress), wantErr: ErrInvalidTransition},
+		{name: "assign failed", form: newForm(1, 42, model.FormStatusNew), assignErr: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newStubRepo(tt.form)
+			repo.assignErr = tt.assignErr
+			operator := &model.User{ID: 100, FirstName: "Operator"}
+
+			form, err := NewService(repo).TakeForm(1, operator)
+			if tt.assignErr != nil {
+				if !errors.Is(err, tt.assignErr) {
+					t.Fatalf("TakeForm error = %v, want %v", err, tt.assignErr)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TakeForm error = %v, want %v", err, tt.wantErr)
+			}
+
+			if _, ok := repo.users[operator.ID]; !ok {
+				t.Error("operator was not saved")
+			}
+			stored := repo.forms[1]
+			if err != nil {
+				if stored.Status != tt.form.Status || stored.Assignee != nil || len(repo.changes) != 0 {
+					t.Errorf("failed take changed form: status %s, assignee %v, history %d", stored.Status, stored.Assignee, len(repo.changes))
+				}
+				return
+			}
+
+			if form.Status != model.FormStatusInProgress || form.Assignee == nil || *form.Assignee != operator.ID {
+				t.Errorf("returned form: status %s, assignee %v", form.Status, form.Assignee)
+			}
+			if stored.Assignee == nil || *stored.Assignee != operator.ID || len(repo.changes) != 1 {
+				t.Errorf("stored form: assignee %v, history %d", stored.Assignee, len(repo.changes))
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/logger"
+	"nstu/internal/model"
+	"nstu/internal/service"
+	"nstu/pkg/tg"
+	"slices"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Ключи callback кнопок уведомления о заявке. Данные имеют вид "<ключ>:<id заявки>"
+const (
+	callbackTake    = "form_take"
+	callbackResolve = "form_resolve"
+	callbackReject  = "form_reject"
+	callbackReply   = "form_reply"
+)
+
+// Admin глобальное состояние с обработчиками кнопок уведомлений в чатах администраторов
+var Admin = tg.State{
+	Global:          true,
+	Context:         false,
+	AtEntranceFunc:  nil,
+	CatchAllFunc:    nil,
+	MessageHandlers: nil,
+	Middlewares:     []tg.Middleware{tg.AdminOnly(fromAdminChat)},
+	CallbackRoutes: []tg.Route{
+		tg.Pattern(formCallbackPattern(callbackTake), tg.Handler{
+			HandleRoute: formAction(func(formID int64, operator *model.User) error {
+				_, err := srv.TakeForm(formID, operator)
+				return err
+			}),
+			Description: "Взять заявку в работу",
+		}),
+		tg.Pattern(formCallbackPattern(callbackResolve), tg.Handler{
+			HandleRoute: formAction(func(formID int64, operator *model.User) error {
+				_, err := srv.ChangeFormStatus(formID, model.FormStatusResolved, operator.ID)
+				return err
+			}),
+			Description: "Отметить заявку решенной",
+		}),
+		tg.Pattern(formCallbackPattern(callbackReject), tg.Handler{
+			HandleRoute: formAction(func(formID int64, operator *model.User) error {
+				_, err := srv.ChangeFormStatus(formID, model.FormStatusRejected, operator.ID)
+				return err
+			}),
+			Description: "Отклонить заявку",
+		}),
+		tg.Pattern(formCallbackPattern(callbackReply), tg.Handler{
+			HandleRoute: handleReply,
+			Description: "Ответить пользователю",
+		}),
+	},
+}
+
+// formCallbackPattern возвращает шаблон маршрута кнопки уведомления о заявке
+func formCallbackPattern(key string) string {
+	return tg.NewCallbackData(key, "{id}")
+}
+
+// fromAdminChat проверяет, что обновление пришло из чата администраторов
+func fromAdminChat(b *tg.Bot, u tgbotapi.Update) bool {
+	chat := u.FromChat()
+	if chat == nil {
+		return false
+	}
+	// В конфигурации ID может быть указан без префикса, сравниваем с найденным ID чата
+	return slices.ContainsFunc(adminChats, func(id int64) bool {
+		return id == chat.ID || b.ResolveChatID(id) == chat.ID
+	})
+}
+
+// statusLabels названия статусов для уведомлений
+var statusLabels = map[model.FormStatus]string{
+	model.FormStatusNew:            "🆕 Новая",
+	model.FormStatusInProgress:     "⏳ В работе",
+	model.FormStatusWaitingForUser: "💬 Ожидает ответа пользователя",
+	model.FormStatusResolved:       "✅ Решена",
+	model.FormStatusRejected:       "❌ Отклонена",
+	model.FormStatusWithdrawn:      "↩️ Отозвана пользователем",
+}
+
+// statusLabel возвращает название статуса заявки
+func statusLabel(status model.FormStatus) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return string(status)
+}
+
+// formKeyboard возвращает кнопки уведомления для текущего статуса заявки.
+// Для закрытых заявок кнопок нет.
+func formKeyboard(form *model.Form) *tgbotapi.InlineKeyboardMarkup {
+	if !form.Status.Open() {
+		return nil
+	}
+
+	id := strconv.FormatInt(form.ID.ID, 10)
+	row := make([]tg.ButtonData, 0, 4)
+	if form.Status != model.FormStatusInProgress {
+		row = append(row, tg.ButtonData{Text: "🙋 Взять", Data: tg.NewCallbackData(callbackTake, id)})
+	}
+	row = append(row,
+		tg.ButtonData{Text: "✅ Решена", Data: tg.NewCallbackData(callbackResolve, id)},
+		tg.ButtonData{Text: "❌ Отклонить", Data: tg.NewCallbackData(callbackReject, id)},
+	)
+
+	keyboard := tg.CreateInlineKeyboard([][]tg.ButtonData{
+		row,
+		{{Text: "💬 Ответить", Data: tg.NewCallbackData(callbackReply, id)}},
+	})
+	return &keyboard
+}
+
+// formAction создает обработчик кнопки уведомления, выполняющий действие над заявкой.
+// После действия уведомление обновляется во всех чатах администраторов.
+func formAction(action func(formID int64, operator *model.User) error) tg.RouteFunc {
+	return func(b *tg.Bot, u tgbotapi.Update, params tg.Params) error {
+		query := u.CallbackQuery
+
+		formID, err := notificationFormID(u, params)
+		if err != nil {
+			b.ShowAlert(query.ID, "Кнопка недоступна")
+			return err
+		}
+
+		operator := &model.User{
+			ID:        query.From.ID,
+			FirstName: query.From.FirstName,
+			LastName:  query.From.LastName,
+			UserName:  query.From.UserName,
+		}
+
+		actionErr := action(formID, operator)
+		if actionErr != nil {
+			b.ShowAlert(query.ID, actionErrorText(actionErr))
+		} else {
+			b.AnswerCallback(query.ID, "Готово")
+		}
+
+		// Обновляем уведомление даже при ошибке: статус мог измениться в другом месте
+		refreshNotifications(formID)
+
+		if actionErr != nil && !isExpectedActionError(actionErr) {
+			return actionErr
+		}
+		return nil
+	}
+}
+
+// handleReply подсказывает оператору, как ответить пользователю
+func handleReply(b *tg.Bot, u tgbotapi.Update, params tg.Params) error {
+	query := u.CallbackQuery
+
+	formID, err := notificationFormID(u, params)
+	if err != nil {
+		b.ShowAlert(query.ID, "Кнопка недоступна")
+		return err
+	}
+
+	request, err := srv.GetFormRequest(formID)
+	if err != nil {
+		b.ShowAlert(query.ID, actionErrorText(err))
+		return err
+	}
+
+	text := "Ответьте (reply) на это сообщение - бот перешлет текст пользователю в личные сообщения"
+	if request.Form.Feedback != "" {
+		text += fmt.Sprintf("\n\nСпособ связи из заявки: %s", request.Form.Feedback)
+	}
+	b.ShowAlert(query.ID, truncate(text, alertMaxLength))
+	return nil
+}
+
+// alertMaxLength максимальная длина текста alert в Telegram
+const alertMaxLength = 200
+
+// truncate обрезает текст до max символов
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// notificationFormID возвращает ID заявки из параметров маршрута и проверяет,
+// что кнопка нажата в уведомлении об этой заявке
+func notificationFormID(u tgbotapi.Update, params tg.Params) (int64, error) {
+	query := u.CallbackQuery
+	formID, err := params.Int64("id")
+	if err != nil {
+		return 0, fmt.Errorf("invalid form id in callback data %q: %w", query.Data, err)
+	}
+
+	if query.Message == nil {
+		return 0, fmt.Errorf("callback without message: %q", query.Data)
+	}
+	notifications, err := srv.ListFormNotifications(formID)
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range notifications {
+		if n.ChatID == query.Message.Chat.ID && n.MessageID == query.Message.MessageID {
+			return formID, nil
+		}
+	}
+	return 0, fmt.Errorf("message %d in chat %d is not a notification of form %d",
+		query.Message.MessageID, query.Message.Chat.ID, formID)
+}
+
+// refreshNotifications обновляет текст и кнопки уведомлений о заявке во всех чатах
+func refreshNotifications(formID int64) {
+	request, err := srv.GetFormRequest(formID)
+	if err != nil {
+		logger.Log.Error().Err(err).Int64("form_id", formID).Msg("Ошибка получения заявки для обновления уведомлений")
+		return
+	}
+	notifications, err := srv.ListFormNotifications(formID)
+	if err != nil {
+		logger.Log.Error().Err(err).Int64("form_id", formID).Msg("Ошибка получения уведомлений о заявке")
+		return
+	}
+
+	text := formatMessage(request)
+	keyboard := formKeyboard(&request.Form)
+	for _, n := range notifications {
+		edit := tgbotapi.NewEditMessageText(n.ChatID, n.MessageID, text)
+		edit.ParseMode = tgbotapi.ModeMarkdownV2
+		// Без ReplyMarkup Telegram убирает кнопки у сообщения
+		edit.ReplyMarkup = keyboard
+
+		// Уведомление уже в актуальном виде, например после повторного нажатия кнопки
+		if _, err := Bot.EditMessage(edit); err != nil && !errors.Is(err, tg.ErrMessageNotModified) {
+			logger.Log.Error().
+				Err(err).
+				Int64("form_id", formID).
+				Int64("chat_id", n.ChatID).
+				Int("message_id", n.MessageID).
+				Msg("Ошибка обновления уведомления о заявке")
+		}
+	}
+}
+
+// actionErrorText возвращает текст ошибки действия над заявкой для оператора
+func actionErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrFormNotFound):
+		return "Заявка не найдена"
+	case errors.Is(err, service.ErrInvalidTransition):
+		return "Действие недоступно для текущего статуса заявки"
+	case errors.Is(err, service.ErrStatusConflict):
+		return "Статус заявки уже изменил другой оператор"
+	default:
+		return "Не удалось выполнить действие, попробуйте позже"
+	}
+}
+
+// isExpectedActionError проверяет, что ошибка вызвана состоянием заявки, а не сбоем
+func isExpectedActionError(err error) bool {
+	return errors.Is(err, service.ErrFormNotFound) ||
+		errors.Is(err, service.ErrInvalidTransition) ||
+		errors.Is(err, service.ErrStatusConflict)
+}
@@ -0,0 +1,118 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/internal/service"
+	"nstu/pkg/tg"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormKeyboard(t *testing.T) {
+	tests := []struct {
+		status model.FormStatus
+		want   [][]string // Данные кнопок по рядам, nil - кнопок нет
+	}{
+		{status: model.FormStatusNew, want: [][]string{{"form_take:7", "form_resolve:7", "form_reject:7"}, {"form_reply:7"}}},
+		{status: model.FormStatusWaitingForUser, want: [][]string{{"form_take:7", "form_resolve:7", "form_reject:7"}, {"form_reply:7"}}},
+		{status: model.FormStatusInProgress, want: [][]string{{"form_resolve:7", "form_reject:7"}, {"form_reply:7"}}},
+		{status: model.FormStatusResolved},
+		{status: model.FormStatusRejected},
+		{status: model.FormStatusWithdrawn},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			form := &model.Form{Status: tt.status}
+			form.ID.ID = 7
+
+			keyboard := formKeyboard(form)
+			if tt.want == nil {
+				if keyboard != nil {
+					t.Errorf("closed form has buttons: %+v", keyboard)
+				}
+				return
+			}
+			if keyboard == nil {
+				t.Fatal("open form has no buttons")
+			}
+
+			var got [][]string
+			for _, row := range keyboard.InlineKeyboard {
+				var data []string
+				for _, button := range row {
+					data = append(data, *button.CallbackData)
+				}
+				got = append(got, data)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buttons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormCallbackRoutes(t *testing.T) {
+	for _, key := range []string{callbackTake, callbackResolve, callbackReject, callbackReply} {
+		t.Run(key, func(t *testing.T) {
+			route := tg.Pattern(formCallbackPattern(key), tg.Handler{})
+			params, ok := route.Match(tg.NewCallbackData(key, "7"))
+			if !ok || params.Get("id") != "7" {
+				t.Errorf("button data matched %v with %v", ok, params)
+			}
+			if _, ok := route.Match(tg.NewCallbackData(key, "7", "8")); ok {
+				t.Error("extra argument must not match")
+			}
+		})
+	}
+}
+
+func TestActionErrorText(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		text     string
+		expected bool
+	}{
+		{name: "not found", err: service.ErrFormNotFound, text: "Заявка не найдена", expected: true},
+		{name: "invalid transition", err: fmt.Errorf("take: %w", service.ErrInvalidTransition), text: "Действие недоступно для текущего статуса заявки", expected: true},
+		{name: "conflict", err: service.ErrStatusConflict, text: "Статус заявки уже изменил другой оператор", expected: true},
+		{name: "failure", err: errors.New("db down"), text: "Не удалось выполнить действие, попробуйте позже", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := actionErrorText(tt.err); got != tt.text {
+				t.Errorf("actionErrorText() = %q, want %q", got, tt.text)
+			}
+			if got := isExpectedActionError(tt.err); got != tt.expected {
+				t.Errorf("isExpectedActionError() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{name: "short", text: "Ответ", max: 10, want: "Ответ"},
+		{name: "exact", text: "Ответ", max: 5, want: "Ответ"},
+		{name: "long", text: "Ответьте на сообщение", max: 6, want: "Ответ…"},
+		{name: "alert limit", text: strings.Repeat("я", 250), max: alertMaxLength, want: strings.Repeat("я", alertMaxLength-1) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.text, tt.max); got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
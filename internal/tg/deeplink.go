@@ -0,0 +1,105 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/logger"
+	"nstu/internal/model"
+	"nstu/internal/service"
+	"nstu/pkg/tg"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Префиксы данных ссылок t.me/bot?start=payload
+const (
+	deepLinkForm   = "form_" // form_<id> - статус заявки для ее автора
+	deepLinkSource = "src_"  // src_<campaign> - источник трафика для следующих заявок
+)
+
+// formSourceKey ключ сессии с источником трафика пользователя
+const formSourceKey = "form_source"
+
+// campaignRegex допустимое название источника трафика
+var campaignRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,60}$`)
+
+// deepLinkRoutes маршруты ссылок t.me/bot?start=payload
+var deepLinkRoutes = []tg.Route{
+	tg.DeepLink(deepLinkForm, tg.Handler{
+		HandleRoute: handleFormLink,
+		Description: "Статус заявки по ссылке",
+	}),
+	tg.DeepLink(deepLinkSource, tg.Handler{
+		HandleRoute: handleSourceLink,
+		Description: "Переход по рекламной ссылке",
+	}),
+}
+
+// formLink возвращает ссылку, по которой автор заявки откроет в боте ее статус
+func formLink(b *tg.Bot, formID int64) string {
+	return b.StartLink(fmt.Sprintf("%s%d", deepLinkForm, formID))
+}
+
+// handleFormLink показывает автору статус заявки из ссылки start=form_<id>.
+// Чужие и несуществующие заявки не показываются.
+func handleFormLink(b *tg.Bot, u tgbotapi.Update, params tg.Params) error {
+	msg := u.Message
+	if !msg.Chat.IsPrivate() || msg.From == nil {
+		return nil
+	}
+
+	formID, err := params.Int64(tg.ParamRest)
+	if err != nil {
+		return send(b, msg.Chat.ID, "Ссылка на заявку неверна", nil)
+	}
+
+	form, err := srv.GetUserForm(msg.From.ID, formID)
+	if errors.Is(err, service.ErrFormNotFound) {
+		return send(b, msg.Chat.ID, fmt.Sprintf("Заявка №%d не найдена", formID), nil)
+	}
+	if err != nil {
+		send(b, msg.Chat.ID, "Не удалось получить заявку, попробуйте позже", nil)
+		return err
+	}
+
+	return send(b, msg.Chat.ID, formatFormStatus(form), nil)
+}
+
+// handleSourceLink запоминает источник трафика из ссылки start=src_<campaign>
+// и приветствует пользователя как обычный /start. Источник сохраняется в заявках пользователя.
+func handleSourceLink(b *tg.Bot, u tgbotapi.Update, params tg.Params) error {
+	campaign := params.Get(tg.ParamRest)
+	if u.Message.Chat.IsPrivate() && u.Message.From != nil && campaignRegex.MatchString(campaign) {
+		if err := b.Session(u.Message.From.ID).Set(formSourceKey, campaign); err != nil {
+			logger.Log.Error().Err(err).Str("source", campaign).Msg("Ошибка сохранения источника трафика")
+		}
+	}
+	return handleStart(b, u)
+}
+
+// formSource возвращает источник трафика пользователя или пустую строку
+func formSource(b *tg.Bot, userID int64) string {
+	source, _, err := tg.SessionValue[string](b.Session(userID), formSourceKey)
+	if err != nil {
+		logger.Log.Error().Err(err).Int64("user_id", userID).Msg("Ошибка чтения источника трафика")
+	}
+	return source
+}
+
+// formatFormStatus форматирует заявку для ее автора
+func formatFormStatus(form *model.Form) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📝 Заявка №%d\n\n", form.ID.ID))
+	builder.WriteString(fmt.Sprintf("📌 Статус: %s\n", statusLabel(form.Status)))
+	builder.WriteString(fmt.Sprintf("📋 Имя: %s\n", form.Name))
+	if form.Feedback != "" {
+		builder.WriteString(fmt.Sprintf("📞 Способ связи: %s\n", form.Feedback))
+	}
+	if form.Comment != "" {
+		builder.WriteString(fmt.Sprintf("💬 Комментарий: %s\n", form.Comment))
+	}
+	builder.WriteString(fmt.Sprintf("🕐 Обновлена: %s", form.UpdatedAt.UpdatedAt.Format("02.01.2006 15:04")))
+	return builder.String()
+}
@@ -0,0 +1,71 @@
+package tg
+
+import (
+	"nstu/internal/model"
+	"nstu/pkg/tg"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCampaignRegex(t *testing.T) {
+	tests := []struct {
+		campaign string
+		want     bool
+	}{
+		{campaign: "vk_spring-2024", want: true},
+		{campaign: "A", want: true},
+		{campaign: strings.Repeat("a", 60), want: true},
+		{campaign: strings.Repeat("a", 61), want: false},
+		{campaign: "", want: false},
+		{campaign: "vk spring", want: false},
+		{campaign: "весна", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.campaign, func(t *testing.T) {
+			if got := campaignRegex.MatchString(tt.campaign); got != tt.want {
+				t.Errorf("campaignRegex(%q) = %v, want %v", tt.campaign, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeepLinkRoutes(t *testing.T) {
+	tests := []struct {
+		text string
+		rest string // Часть данных после префикса, пустая - маршрут не найден
+	}{
+		{text: "/start form_12", rest: "12"},
+		{text: "/start src_vk_spring", rest: "vk_spring"},
+		{text: "/start other", rest: ""},
+		{text: "/start", rest: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rest := ""
+			for _, route := range deepLinkRoutes {
+				if params, ok := route.Match(tt.text); ok {
+					rest = params.Get(tg.ParamRest)
+					break
+				}
+			}
+			if rest != tt.rest {
+				t.Errorf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestFormatFormStatus(t *testing.T) {
+	form := &model.Form{Name: "Ivan", Comment: "Вопрос", Status: model.FormStatusInProgress, Source: "vk_spring"}
+	form.ID.ID = 12
+	form.UpdatedAt.UpdatedAt = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	want := "📝 Заявка №12\n\n📌 Статус: " + statusLabel(model.FormStatusInProgress) +
+		"\n📋 Имя: Ivan\n💬 Комментарий: Вопрос\n🕐 Обновлена: 01.05.2024 12:30"
+	if got := formatFormStatus(form); got != want {
+		t.Errorf("formatFormStatus() = %q, want %q", got, want)
+	}
+}
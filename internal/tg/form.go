@@ -0,0 +1,431 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	apivalidator "nstu/internal/api/validator"
+	"nstu/internal/logger"
+	"nstu/internal/model"
+	"nstu/pkg/tg"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Состояния заполнения заявки через бота
+const (
+	stateFormName     = "form_name"
+	stateFormFeedback = "form_feedback"
+	stateFormComment  = "form_comment"
+	stateFormPreview  = "form_preview"
+)
+
+// Ключи callback кнопок заполнения заявки
+const (
+	callbackFormTelegram     = "form_use_telegram"
+	callbackFormSkip         = "form_skip"
+	callbackFormConfirm      = "form_confirm"
+	callbackFormEdit         = "form_edit"
+	callbackFormCancel       = "form_cancel"
+	callbackFormEditName     = "form_edit_name"
+	callbackFormEditFeedback = "form_edit_feedback"
+	callbackFormEditComment  = "form_edit_comment"
+)
+
+// formDraftKey ключ черновика заявки в сессии пользователя
+const formDraftKey = "form_draft"
+
+// formDraft черновик заявки, заполняемый по шагам
+type formDraft struct {
+	Name     string `json:"name"`
+	Feedback string `json:"feedback"`
+	Comment  string `json:"comment"`
+	Editing  bool   `json:"editing"` // Поле изменяется из предпросмотра, после ввода вернуться к нему
+}
+
+// FormName шаг ввода имени
+var FormName = tg.State{
+	Global:  false,
+	Context: true,
+	AtEntranceFunc: &tg.Handler{
+		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+			return send(b, u.FromChat().ID, "Как к вам обращаться?", cancelKeyboard())
+		},
+	},
+	CatchAllFunc: &tg.Handler{
+		Handle: formInput("Name", func(d *formDraft, value string) { d.Name = value }, stateFormFeedback),
+	},
+	CallbackHandlers: map[string]tg.Handler{
+		callbackFormCancel: {Handle: cancelForm, Description: "Отменить заявку"},
+	},
+	Transitions: []string{stateFormFeedback, stateFormPreview, "menu"},
+}
+
+// FormFeedback шаг ввода способа обратной связи
+var FormFeedback = tg.State{
+	Global:  false,
+	Context: true,
+	AtEntranceFunc: &tg.Handler{
+		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+			keyboard := tg.CreateInlineKeyboard([][]tg.ButtonData{
+				{{Text: "✈️ Написать мне в Telegram", Data: callbackFormTelegram}},
+				{{Text: "⏭ Пропустить", Data: callbackFormSkip}},
+				{{Text: "✖️ Отменить", Data: callbackFormCancel}},
+			})
+			if err := send(b, u.FromChat().ID, "Как с вами связаться? Напишите телефон, почту или выберите Telegram", keyboard); err != nil {
+				return err
+			}
+
+			// Запросить контакт можно только кнопкой обычной клавиатуры
+			contactKeyboard := tgbotapi.NewOneTimeReplyKeyboard(
+				tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonContact("📱 Отправить номер телефона")),
+			)
+			return send(b, u.FromChat().ID, "Или поделитесь номером кнопкой ниже", contactKeyboard)
+		},
+	},
+	CatchAllFunc: &tg.Handler{
+		Handle: formInput("Feedback", func(d *formDraft, value string) { d.Feedback = value }, stateFormComment),
+	},
+	ContactHandler: &tg.Handler{
+		Handle:      formContact,
+		Description: "Отправить номер телефона",
+	},
+	CallbackHandlers: map[string]tg.Handler{
+		callbackFormTelegram: {
+			Handle:      formChoice(func(d *formDraft, from *tgbotapi.User) { d.Feedback = telegramContact(from) }, stateFormComment),
+			Description: "Связаться через Telegram",
+		},
+		callbackFormSkip: {
+			Handle:      formChoice(func(d *formDraft, _ *tgbotapi.User) { d.Feedback = "" }, stateFormComment),
+			Description: "Пропустить способ связи",
+		},
+		callbackFormCancel: {Handle: cancelForm, Description: "Отменить заявку"},
+	},
+	Transitions: []string{stateFormComment, stateFormPreview, "menu"},
+}
+
+// FormComment шаг ввода комментария
+var FormComment = tg.State{
+	Global:  false,
+	Context: true,
+	AtEntranceFunc: &tg.Handler{
+		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+			keyboard := tg.CreateInlineKeyboard([][]tg.ButtonData{
+				{{Text: "⏭ Пропустить", Data: callbackFormSkip}},
+				{{Text: "✖️ Отменить", Data: callbackFormCancel}},
+			})
+			return send(b, u.FromChat().ID, "Опишите ваш вопрос или пожелание", keyboard)
+		},
+	},
+	CatchAllFunc: &tg.Handler{
+		Handle: formInput("Comment", func(d *formDraft, value string) { d.Comment = value }, stateFormPreview),
+	},
+	CallbackHandlers: map[string]tg.Handler{
+		callbackFormSkip: {
+			Handle:      formChoice(func(d *formDraft, _ *tgbotapi.User) { d.Comment = "" }, stateFormPreview),
+			Description: "Пропустить комментарий",
+		},
+		callbackFormCancel: {Handle: cancelForm, Description: "Отменить заявку"},
+	},
+	Transitions: []string{stateFormPreview, "menu"},
+}
+
+// FormPreview предпросмотр заявки перед отправкой
+var FormPreview = tg.State{
+	Global:  false,
+	Context: true,
+	AtEntranceFunc: &tg.Handler{
+		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+			draft, err := loadDraft(b, u.SentFrom().ID)
+			if err != nil {
+				return err
+			}
+			keyboard := tg.CreateInlineKeyboard([][]tg.ButtonData{
+				{{Text: "✅ Отправить", Data: callbackFormConfirm}},
+				{{Text: "✏️ Изменить", Data: callbackFormEdit}, {Text: "✖️ Отменить", Data: callbackFormCancel}},
+			})
+			return send(b, u.FromChat().ID, formatDraft(draft), keyboard)
+		},
+	},
+	CatchAllFunc: &tg.Handler{
+		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+			if u.Message == nil || !u.Message.Chat.IsPrivate() || u.Message.ReplyToMessage != nil {
+				return nil
+			}
+			return send(b, u.Message.Chat.ID, "Отправьте заявку или измените ее кнопками под предпросмотром", nil)
+		},
+	},
+	CallbackHandlers: map[string]tg.Handler{
+		callbackFormConfirm: {Handle: confirmForm, Description: "Отправить заявку"},
+		callbackFormEdit: {
+			Handle: func(b *tg.Bot, u tgbotapi.Update) error {
+				b.AnswerCallback(u.CallbackQuery.ID, "")
+				keyboard := tg.CreateInlineKeyboard([][]tg.ButtonData{
+					{{Text: "Имя", Data: callbackFormEditName}},
+					{{Text: "Способ связи", Data: callbackFormEditFeedback}},
+					{{Text: "Комментарий", Data: callbackFormEditComment}},
+				})
+				return send(b, u.FromChat().ID, "Что изменить?", keyboard)
+			},
+			Description: "Изменить заявку",
+		},
+		callbackFormEditName:     {Handle: editField(stateFormName), Description: "Изменить имя"},
+		callbackFormEditFeedback: {Handle: editField(stateFormFeedback), Description: "Изменить способ связи"},
+		callbackFormEditComment:  {Handle: editField(stateFormComment), Description: "Изменить комментарий"},
+		callbackFormCancel:       {Handle: cancelForm, Description: "Отменить заявку"},
+	},
+	Transitions: []string{stateFormName, stateFormFeedback, stateFormComment, "menu"},
+}
+
+// startForm начинает заполнение новой заявки
+func startForm(b *tg.Bot, u tgbotapi.Update) error {
+	if !u.FromChat().IsPrivate() {
+		return send(b, u.FromChat().ID, "Оставить заявку можно в личных сообщениях с ботом", nil)
+	}
+	if err := b.Session(u.SentFrom().ID).Set(formDraftKey, formDraft{}); err != nil {
+		return err
+	}
+	b.SetUserState(u.SentFrom().ID, stateFormName, false, &u)
+	return nil
+}
+
+// formInput создает обработчик текстового ответа на шаге заявки.
+// field - поле model.Form, по тегу validate которого проверяется ответ.
+func formInput(field string, set func(d *formDraft, value string), next string) tg.HandlerFunc {
+	return func(b *tg.Bot, u tgbotapi.Update) error {
+		msg := u.Message
+		// Ответы (reply) в личном чате относятся к переписке по заявкам
+		if msg == nil || !msg.Chat.IsPrivate() || msg.ReplyToMessage != nil {
+			return nil
+		}
+		if msg.Text == "" {
+			return send(b, msg.Chat.ID, "Отправьте ответ текстом", nil)
+		}
+
+		value := strings.TrimSpace(msg.Text)
+		if problem := formFieldProblem(field, value); problem != "" {
+			return send(b, msg.Chat.ID, problem, nil)
+		}
+
+		return nextStep(b, u, func(d *formDraft) { set(d, value) }, next)
+	}
+}
+
+// formChoice создает обработчик кнопки, заполняющей поле заявки
+func formChoice(set func(d *formDraft, from *tgbotapi.User), next string) tg.HandlerFunc {
+	return func(b *tg.Bot, u tgbotapi.Update) error {
+		b.AnswerCallback(u.CallbackQuery.ID, "")
+		return nextStep(b, u, func(d *formDraft) { set(d, u.CallbackQuery.From) }, next)
+	}
+}
+
+// nextStep сохраняет изменение черновика и переходит к следующему шагу.
+// При изменении поля из предпросмотра возвращает к предпросмотру.
+func nextStep(b *tg.Bot, u tgbotapi.Update, change func(d *formDraft), next string) error {
+	userID := u.SentFrom().ID
+	draft, err := loadDraft(b, userID)
+	if err != nil {
+		return err
+	}
+
+	change(draft)
+	if draft.Editing {
+		draft.Editing = false
+		next = stateFormPreview
+	}
+
+	if err := b.Session(userID).Set(formDraftKey, draft); err != nil {
+		return err
+	}
+	b.SetUserState(userID, next, false, &u)
+	return nil
+}
+
+// formContact сохраняет номер телефона из отправленного контакта как способ связи
+func formContact(b *tg.Bot, u tgbotapi.Update) error {
+	msg := u.Message
+	if !msg.Chat.IsPrivate() {
+		return nil
+	}
+
+	value := "Телефон " + msg.Contact.PhoneNumber
+	if problem := formFieldProblem("Feedback", value); problem != "" {
+		return send(b, msg.Chat.ID, problem, nil)
+	}
+
+	// Убираем клавиатуру с кнопкой контакта
+	if err := send(b, msg.Chat.ID, "📱 Номер сохранен", tgbotapi.NewRemoveKeyboard(false)); err != nil {
+		return err
+	}
+	return nextStep(b, u, func(d *formDraft) { d.Feedback = value }, stateFormComment)
+}
+
+// editField создает обработчик кнопки изменения поля из предпросмотра
+func editField(state string) tg.HandlerFunc {
+	return func(b *tg.Bot, u tgbotapi.Update) error {
+		b.AnswerCallback(u.CallbackQuery.ID, "")
+
+		userID := u.SentFrom().ID
+		draft, err := loadDraft(b, userID)
+		if err != nil {
+			return err
+		}
+		draft.Editing = true
+		if err := b.Session(userID).Set(formDraftKey, draft); err != nil {
+			return err
+		}
+		b.SetUserState(userID, state, false, &u)
+		return nil
+	}
+}
+
+// confirmForm отправляет заявку тем же путем, что и API
+func confirmForm(b *tg.Bot, u tgbotapi.Update) error {
+	query := u.CallbackQuery
+	draft, err := loadDraft(b, query.From.ID)
+	if err != nil {
+		b.ShowAlert(query.ID, "Черновик заявки не найден, начните заново")
+		return err
+	}
+
+	user := &model.User{
+		ID:        query.From.ID,
+		FirstName: query.From.FirstName,
+		LastName:  query.From.LastName,
+		UserName:  query.From.UserName,
+	}
+	form := &model.Form{
+		Name:     draft.Name,
+		Feedback: draft.Feedback,
+		Comment:  draft.Comment,
+		Source:   formSource(b, query.From.ID),
+	}
+	if err := apivalidator.Struct(form); err != nil {
+		b.ShowAlert(query.ID, "Заявка заполнена неверно, измените ее")
+		return nil
+	}
+
+	if err := srv.CreateForm(user, form); err != nil {
+		b.ShowAlert(query.ID, "Не удалось отправить заявку, попробуйте позже")
+		return err
+	}
+	b.AnswerCallback(query.ID, "Заявка отправлена")
+
+	// Убираем кнопки у предпросмотра, чтобы заявку нельзя было отправить повторно
+	if query.Message != nil {
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, formatDraft(draft))
+		if _, err := b.EditMessage(edit); err != nil {
+			logger.Log.Error().Err(err).Msg("Ошибка обновления предпросмотра заявки")
+		}
+	}
+
+	err = send(b, u.FromChat().ID, formSentText(b, form.ID.ID), nil)
+	finishForm(b, u)
+	return err
+}
+
+// cancelForm прерывает заполнение заявки
+func cancelForm(b *tg.Bot, u tgbotapi.Update) error {
+	if u.CallbackQuery != nil {
+		b.AnswerCallback(u.CallbackQuery.ID, "Заявка отменена")
+	}
+	err := send(b, u.FromChat().ID, "Заявка отменена", nil)
+	finishForm(b, u)
+	return err
+}
+
+// finishForm удаляет черновик и возвращает пользователя в меню
+func finishForm(b *tg.Bot, u tgbotapi.Update) {
+	userID := u.SentFrom().ID
+	if err := b.Session(userID).Delete(formDraftKey); err != nil {
+		logger.Log.Error().Err(err).Int64("user_id", userID).Msg("Ошибка удаления черновика заявки")
+	}
+	b.SetUserState(userID, "menu", false, &u)
+}
+
+// loadDraft возвращает черновик заявки из сессии пользователя
+func loadDraft(b *tg.Bot, userID int64) (*formDraft, error) {
+	draft, ok, err := tg.SessionValue[formDraft](b.Session(userID), formDraftKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("form draft not found")
+	}
+	return &draft, nil
+}
+
+// formatDraft форматирует черновик заявки для предпросмотра
+func formatDraft(d *formDraft) string {
+	var builder strings.Builder
+	builder.WriteString("📝 Проверьте заявку\n\n")
+	builder.WriteString(fmt.Sprintf("📋 Имя: %s\n", d.Name))
+	if d.Feedback != "" {
+		builder.WriteString(fmt.Sprintf("📞 Способ связи: %s\n", d.Feedback))
+	}
+	if d.Comment != "" {
+		builder.WriteString(fmt.Sprintf("\n💬 Комментарий:\n%s\n", d.Comment))
+	}
+	return builder.String()
+}
+
+// telegramContact возвращает контакт пользователя в Telegram
+func telegramContact(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return "Telegram @" + user.UserName
+	}
+	return fmt.Sprintf("Telegram tg://user?id=%d", user.ID)
+}
+
+// cancelKeyboard кнопка отмены заполнения заявки
+func cancelKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tg.CreateInlineKeyboard([][]tg.ButtonData{{{Text: "✖️ Отменить", Data: callbackFormCancel}}})
+}
+
+// send отправляет текст без разметки. replyMarkup может быть nil.
+func send(b *tg.Bot, chatID int64, text string, replyMarkup interface{}) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	if replyMarkup != nil {
+		msg.ReplyMarkup = replyMarkup
+	}
+	_, err := b.SendMessage(msg)
+	return err
+}
+
+// formFieldProblem проверяет значение по тегу validate поля model.Form
+// и возвращает описание ошибки для пользователя или пустую строку
+func formFieldProblem(field, value string) string {
+	f, ok := reflect.TypeOf(model.Form{}).FieldByName(field)
+	if !ok {
+		logger.Log.Error().Str("field", field).Msg("Неизвестное поле заявки")
+		return "Недопустимое значение"
+	}
+
+	err := apivalidator.Var(value, f.Tag.Get("validate"))
+	if err == nil {
+		return ""
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) == 0 {
+		logger.Log.Error().Err(err).Str("field", field).Msg("Ошибка проверки поля заявки")
+		return "Недопустимое значение"
+	}
+
+	fe := validationErrors[0]
+	switch fe.Tag() {
+	case "required":
+		return "Ответ не может быть пустым"
+	case "max":
+		return fmt.Sprintf("Слишком длинный ответ, максимум %s символов", fe.Param())
+	default:
+		return "Недопустимое значение"
+	}
+}
+
+// formSentText сообщение об отправленной заявке со ссылкой на ее статус
+func formSentText(b *tg.Bot, formID int64) string {
+	return fmt.Sprintf("✅ Заявка №%d отправлена. Ответ придет в этот чат.\nСтатус заявки: %s", formID, formLink(b, formID))
+}
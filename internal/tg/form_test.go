@@ -0,0 +1,121 @@
+package tg
+
+import (
+	"nstu/pkg/tg"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFormFieldProblem(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		value string
+		want  string
+	}{
+		{name: "valid name", field: "Name", value: "Иван", want: ""},
+		{name: "empty name", field: "Name", value: "", want: "Ответ не может быть пустым"},
+		{name: "long name", field: "Name", value: strings.Repeat("я", 129), want: "Слишком длинный ответ, максимум 128 символов"},
+		{name: "name at limit", field: "Name", value: strings.Repeat("я", 128), want: ""},
+		{name: "empty feedback", field: "Feedback", value: "", want: ""},
+		{name: "long feedback", field: "Feedback", value: strings.Repeat("a", 257), want: "Слишком длинный ответ, максимум 256 символов"},
+		{name: "long comment", field: "Comment", value: strings.Repeat("a", 513), want: "Слишком длинный ответ, максимум 512 символов"},
+		{name: "unknown field", field: "Phone", value: "123", want: "Недопустимое значение"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formFieldProblem(tt.field, tt.value); got != tt.want {
+				t.Errorf("formFieldProblem(%s) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDraft(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft formDraft
+		want  string
+	}{
+		{
+			name:  "full",
+			draft: formDraft{Name: "Иван", Feedback: "+79990000000", Comment: "Не работает пропуск"},
+			want:  "📝 Проверьте заявку\n\n📋 Имя: Иван\n📞 Способ связи: +79990000000\n\n💬 Комментарий:\nНе работает пропуск\n",
+		},
+		{
+			name:  "name only",
+			draft: formDraft{Name: "Иван"},
+			want:  "📝 Проверьте заявку\n\n📋 Имя: Иван\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDraft(&tt.draft); got != tt.want {
+				t.Errorf("formatDraft() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelegramContact(t *testing.T) {
+	tests := []struct {
+		name string
+		user tgbotapi.User
+		want string
+	}{
+		{name: "username", user: tgbotapi.User{ID: 42, UserName: "ivan"}, want: "Telegram @ivan"},
+		{name: "without username", user: tgbotapi.User{ID: 42}, want: "Telegram tg://user?id=42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telegramContact(&tt.user); got != tt.want {
+				t.Errorf("telegramContact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Каждый шаг заявки можно отменить, а кнопки предпросмотра ведут к своим шагам
+func TestFormWizardStates(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     tg.State
+		callbacks []string
+	}{
+		{name: stateFormName, state: FormName, callbacks: []string{callbackFormCancel}},
+		{name: stateFormFeedback, state: FormFeedback, callbacks: []string{callbackFormTelegram, callbackFormSkip, callbackFormCancel}},
+		{name: stateFormComment, state: FormComment, callbacks: []string{callbackFormSkip, callbackFormCancel}},
+		{
+			name:  stateFormPreview,
+			state: FormPreview,
+			callbacks: []string{
+				callbackFormConfirm, callbackFormEdit, callbackFormCancel,
+				callbackFormEditName, callbackFormEditFeedback, callbackFormEditComment,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.state.Context || tt.state.Global {
+				t.Error("form step must be a local state with context")
+			}
+			if tt.state.AtEntranceFunc == nil || tt.state.CatchAllFunc == nil {
+				t.Error("form step must ask a question and accept any answer")
+			}
+			for _, key := range tt.callbacks {
+				if _, ok := tt.state.CallbackHandlers[key]; !ok {
+					t.Errorf("callback %q is not handled", key)
+				}
+			}
+			if len(tt.state.CallbackHandlers) != len(tt.callbacks) {
+				t.Errorf("handled callbacks = %d, want %d", len(tt.state.CallbackHandlers), len(tt.callbacks))
+			}
+		})
+	}
+}
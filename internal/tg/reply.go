@@ -0,0 +1,168 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/logger"
+	"nstu/internal/model"
+	"nstu/internal/service"
+	"nstu/pkg/tg"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleReplies пересылает ответы (reply) между чатом администраторов и пользователем.
+// Оператор отвечает на уведомление о заявке или на сообщение пользователя в чате администраторов,
+// пользователь - на сообщение оператора в личном чате с ботом.
+func handleReplies(b *tg.Bot, u tgbotapi.Update) error {
+	msg := u.Message
+	if msg == nil || msg.ReplyToMessage == nil || msg.From == nil {
+		return nil
+	}
+
+	if msg.Chat.IsPrivate() {
+		return handleUserReply(b, msg)
+	}
+	return handleOperatorReply(b, msg)
+}
+
+// handleOperatorReply доставляет ответ оператора автору заявки
+func handleOperatorReply(b *tg.Bot, msg *tgbotapi.Message) error {
+	formID, err := srv.FindFormByAdminMessage(msg.Chat.ID, msg.ReplyToMessage.MessageID)
+	if errors.Is(err, service.ErrFormNotFound) {
+		// Ответ не на сообщение бота о заявке
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if msg.Text == "" {
+		replyTo(b, msg, "Пользователю можно отправить только текстовое сообщение")
+		return nil
+	}
+
+	request, err := srv.GetFormRequest(formID)
+	if err != nil {
+		return err
+	}
+	if !request.Form.Status.Open() {
+		replyTo(b, msg, fmt.Sprintf("Заявка №%d закрыта, сообщение не отправлено", formID))
+		return nil
+	}
+
+	text := fmt.Sprintf("💬 Ответ по вашей заявке №%d:\n\n%s\n\nЧтобы ответить, используйте «Ответить» на этом сообщении.", formID, msg.Text)
+	delivered, err := b.SendMessage(tgbotapi.NewMessage(request.User.ID, text))
+	if errors.Is(err, tg.ErrBotBlocked) {
+		replyTo(b, msg, "Не удалось доставить сообщение: пользователь заблокировал бота")
+		return fmt.Errorf("failed to deliver operator reply for form %d: %w", formID, err)
+	}
+	if err != nil {
+		replyTo(b, msg, "Не удалось доставить сообщение: пользователь не начал диалог с ботом или заблокировал его")
+		return fmt.Errorf("failed to deliver operator reply for form %d: %w", formID, err)
+	}
+
+	err = srv.AddOperatorMessage(&model.FormMessage{
+		FormID:         formID,
+		AuthorID:       msg.From.ID,
+		Text:           msg.Text,
+		AdminChatID:    msg.Chat.ID,
+		AdminMessageID: msg.MessageID,
+		UserChatID:     delivered.Chat.ID,
+		UserMessageID:  delivered.MessageID,
+	})
+	if err != nil {
+		logger.Log.Error().Err(err).Int64("form_id", formID).Msg("Ошибка сохранения ответа оператора")
+	}
+
+	replyTo(b, msg, "✉️ Доставлено пользователю")
+	refreshNotifications(formID)
+	return nil
+}
+
+// handleUserReply пересылает ответ пользователя в чат администраторов,
+// ответом на исходное уведомление о заявке
+func handleUserReply(b *tg.Bot, msg *tgbotapi.Message) error {
+	operatorMessage, err := srv.FindFormMessageByUserMessage(msg.Chat.ID, msg.ReplyToMessage.MessageID)
+	if errors.Is(err, service.ErrFormNotFound) {
+		// Ответ не на сообщение оператора
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	formID := operatorMessage.FormID
+
+	if msg.Text == "" {
+		replyTo(b, msg, "Пока можно отправить только текстовое сообщение")
+		return nil
+	}
+
+	request, err := srv.GetFormRequest(formID)
+	if err != nil {
+		return err
+	}
+	if request.User.ID != msg.From.ID {
+		return nil
+	}
+	if !request.Form.Status.Open() {
+		replyTo(b, msg, fmt.Sprintf("Заявка №%d закрыта, сообщение не отправлено", formID))
+		return nil
+	}
+
+	forward := tgbotapi.NewMessage(operatorMessage.AdminChatID, fmt.Sprintf(
+		"💬 Ответ пользователя %s по заявке №%d:\n\n%s",
+		plainUser(&request.User), formID, msg.Text,
+	))
+	// Отвечаем на исходное уведомление, если оно есть в этом чате
+	forward.ReplyToMessageID = operatorMessage.AdminMessageID
+	if notification, err := srv.GetFormNotification(formID, operatorMessage.AdminChatID); err == nil {
+		forward.ReplyToMessageID = notification.MessageID
+	}
+	forward.AllowSendingWithoutReply = true
+
+	forwarded, err := b.SendMessage(forward)
+	if err != nil {
+		replyTo(b, msg, "Не удалось отправить сообщение, попробуйте позже")
+		return fmt.Errorf("failed to forward user reply for form %d: %w", formID, err)
+	}
+
+	err = srv.AddUserMessage(&model.FormMessage{
+		FormID:         formID,
+		AuthorID:       msg.From.ID,
+		Text:           msg.Text,
+		AdminChatID:    forwarded.Chat.ID,
+		AdminMessageID: forwarded.MessageID,
+		UserChatID:     msg.Chat.ID,
+		UserMessageID:  msg.MessageID,
+	})
+	if err != nil {
+		logger.Log.Error().Err(err).Int64("form_id", formID).Msg("Ошибка сохранения ответа пользователя")
+	}
+
+	replyTo(b, msg, "✉️ Сообщение передано оператору")
+	refreshNotifications(formID)
+	return nil
+}
+
+// replyTo отправляет текст ответом на сообщение
+func replyTo(b *tg.Bot, msg *tgbotapi.Message, text string) {
+	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
+	reply.ReplyToMessageID = msg.MessageID
+	reply.AllowSendingWithoutReply = true
+	if _, err := b.SendMessage(reply); err != nil {
+		logger.Log.Error().Err(err).Int64("chat_id", msg.Chat.ID).Msg("Ошибка отправки ответа")
+	}
+}
+
+// plainUser форматирует имя пользователя Telegram без разметки
+func plainUser(user *model.User) string {
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	if user.UserName != "" {
+		name += fmt.Sprintf(" (@%s)", user.UserName)
+	}
+	return name
+}
@@ -0,0 +1,27 @@
+package tg
+
+import (
+	"nstu/internal/model"
+	"testing"
+)
+
+func TestPlainUser(t *testing.T) {
+	tests := []struct {
+		name string
+		user model.User
+		want string
+	}{
+		{name: "first name", user: model.User{FirstName: "Ivan"}, want: "Ivan"},
+		{name: "full name", user: model.User{FirstName: "Ivan", LastName: "Petrov"}, want: "Ivan Petrov"},
+		{name: "username", user: model.User{FirstName: "Ivan", UserName: "ivan"}, want: "Ivan (@ivan)"},
+		{name: "markdown stays plain", user: model.User{FirstName: "*Ivan*", LastName: "Petrov", UserName: "ivan_p"}, want: "*Ivan* Petrov (@ivan_p)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plainUser(&tt.user); got != tt.want {
+				t.Errorf("plainUser() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
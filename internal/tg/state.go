@@ -2,13 +2,30 @@ package tg
 
 import (
 	"nstu/pkg/tg"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// Кнопки меню
+const (
+	menuFormButton   = "📝 Оставить заявку" // Заполнение заявки в боте
+	menuWebAppButton = "🧾 Открыть форму"   // Открытие Mini App
+)
+
 var states = map[string]tg.State{
-	"start": Start,
-	"menu":  Menu,
+	"start":           Start,
+	"menu":            Menu,
+	"admin":           Admin,
+	stateFormName:     FormName,
+	stateFormFeedback: FormFeedback,
+	stateFormComment:  FormComment,
+	stateFormPreview:  FormPreview,
+}
+
+// States возвращает карту состояний бота, например для диаграммы в cmd/diagram
+func States() map[string]tg.State {
+	return states
 }
 
 var Start = tg.State{
@@ -18,21 +35,53 @@ var Start = tg.State{
 	CatchAllFunc:   nil,
 	MessageHandlers: map[string]tg.Handler{
 		"/start": {
-			Handle: func(b *tg.Bot, u tgbotapi.Update) error {
-				b.SendMessage(tgbotapi.NewMessage(u.Message.Chat.ID, "Привет, я бот для студентов НГТУ"))
-				return nil
-			},
-			Description: "Начало работы",
+			Handle:       handleStart,
+			Description:  "Начало работы",
+			Descriptions: map[string]string{"en": "Get started"},
 		},
+		"/help": tg.HelpHandler("Доступные команды:"),
 		"/menu": {
 			Handle: func(b *tg.Bot, u tgbotapi.Update) error {
 				b.SetUserState(u.Message.Chat.ID, "menu", false, &u)
 				return nil
 			},
-			Description: "Выполянет переход в меню",
+			Description:  "Перейти в меню",
+			Descriptions: map[string]string{"en": "Open the menu"},
+		},
+		"/form": {
+			Handle:       startForm,
+			Description:  "Оставить заявку",
+			Descriptions: map[string]string{"en": "Submit a request"},
+		},
+		// Кнопка меню остается на экране, поэтому обрабатывается в любом состоянии.
+		// Текст сообщения приводится к нижнему регистру перед поиском обработчика.
+		strings.ToLower(menuFormButton): {
+			Handle:      startForm,
+			Description: "Оставить заявку",
 		},
+		"/cancel": {
+			Handle:       cancelForm,
+			Description:  "Отменить заполнение заявки",
+			Descriptions: map[string]string{"en": "Cancel the request"},
+		},
+	},
+	// Ссылки t.me/bot?start=payload, остальные данные ссылок обрабатывает "/start"
+	MessageRoutes: deepLinkRoutes,
+	WebAppDataHandler: &tg.Handler{
+		Handle:      handleWebAppForm,
+		Description: "Заявка из Mini App",
 	},
 	CallbackHandlers: nil,
+	Transitions:      []string{"menu", stateFormName},
+}
+
+// handleStart приветствует пользователя и в личном чате открывает меню
+func handleStart(b *tg.Bot, u tgbotapi.Update) error {
+	b.SendMessage(tgbotapi.NewMessage(u.Message.Chat.ID, "Привет, я бот для студентов НГТУ"))
+	if u.Message.Chat.IsPrivate() {
+		b.SetUserState(u.Message.From.ID, "menu", false, &u)
+	}
+	return nil
 }
 
 var Menu = tg.State{
@@ -40,17 +89,24 @@ var Menu = tg.State{
 	Context: true,
 	AtEntranceFunc: &tg.Handler{
 		Handle: func(b *tg.Bot, u tgbotapi.Update) error {
-			b.SendMessage(tgbotapi.NewMessage(u.Message.Chat.ID, "Что хотите сделать?\n1)Арзуб2)Выйти"))
+			msg := tgbotapi.NewMessage(u.FromChat().ID, "Что хотите сделать?")
+			msg.ReplyMarkup = menuKeyboard()
+			b.SendMessage(msg)
 			return nil
 		},
 	},
-	CatchAllFunc: nil,
-	MessageHandlers: map[string]tg.Handler{
-		"арбуз": tg.Handler{
-			Handle: func(b *tg.Bot, u tgbotapi.Update) error {
-				b.SendMessage(tgbotapi.NewMessage(u.Message.Chat.ID, "АрбузАрбузАрбуз"))
-				return nil
-			},
-		},
-	},
+	CatchAllFunc:    nil,
+	MessageHandlers: nil,
+}
+
+// menuKeyboard клавиатура меню. Если задан адрес Mini App, добавляется кнопка для его открытия:
+// только из Mini App, открытого такой кнопкой, можно отправить заявку через sendData
+func menuKeyboard() interface{} {
+	if webAppURL == "" {
+		return tg.CreateKeyboard([]string{menuFormButton}, 1)
+	}
+	return tg.NewWebAppReplyKeyboard(
+		[]any{tgbotapi.NewKeyboardButton(menuFormButton)},
+		[]any{tg.WebAppKeyboardButton{Text: menuWebAppButton, WebApp: tg.WebAppInfo{URL: webAppURL}}},
+	)
 }
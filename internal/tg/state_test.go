@@ -0,0 +1,36 @@
+package tg
+
+import (
+	"nstu/pkg/tg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestStateDiagramsUpToDate проверяет, что диаграммы в docs совпадают с картой состояний.
+// Обновить их можно командой из cmd/diagram.
+func TestStateDiagramsUpToDate(t *testing.T) {
+	tests := []struct {
+		format tg.DiagramFormat
+		path   string
+	}{
+		{format: tg.DiagramMermaid, path: "states.mmd"},
+		{format: tg.DiagramDOT, path: "states.dot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join("..", "..", "docs", tt.path))
+			if err != nil {
+				t.Fatalf("failed to read diagram: %v", err)
+			}
+			got, err := tg.RenderDiagram(States(), tt.format)
+			if err != nil {
+				t.Fatalf("RenderDiagram failed: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("docs/%s is outdated, regenerate it with cmd/diagram", tt.path)
+			}
+		})
+	}
+}
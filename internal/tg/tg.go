@@ -1,18 +1,31 @@
 package tg
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"nstu/internal/logger"
 	"nstu/internal/model"
+	"nstu/internal/service"
 	"nstu/pkg/tg"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
 )
 
 var (
-	Bot *tg.Bot
+	Bot        *tg.Bot
+	srv        service.Servicer
+	adminChats []int64 // Чаты администраторов, в которые приходят уведомления о заявках
+	webAppURL  string  // Адрес Mini App для кнопки в меню, пустой - кнопки нет
+
+	notifierStop     = make(chan struct{}) // Закрывается при остановке рассылки уведомлений
+	notifierStopOnce sync.Once             // Защищает notifierStop от повторного закрытия при повторном Shutdown
+	notifierDone     = make(chan struct{}) // Закрывается после отправки оставшихся уведомлений
 )
 
 type Config interface {
@@ -20,84 +33,283 @@ type Config interface {
 	GetExpiration() time.Duration
 	GetCleanupInterval() time.Duration
 	GetMessageChats() *[]int64
+	GetUpdateMode() string
+	GetWebhookURL() string
+	GetWebhookSecret() string
+	GetWebAppURL() string
+	GetStateStore() string
+	GetStateFile() string
+	GetWorkers() int
+	GetQueueSize() int
+	GetQueueOverflow() string
+}
+
+// Хранилища состояний пользователей
+const (
+	stateStoreMemory   = "memory"
+	stateStorePostgres = "postgres"
+	stateStoreFile     = "file"
+)
+
+// newStateStore создает хранилище состояний, выбранное в конфигурации
+func newStateStore(config Config, db *sql.DB) (tg.StateStore, error) {
+	switch config.GetStateStore() {
+	case "", stateStoreMemory:
+		return tg.NewMemoryStore(config.GetCleanupInterval()), nil
+	case stateStorePostgres:
+		return tg.NewPostgresStore(db, tg.DefaultStateTable)
+	case stateStoreFile:
+		return tg.NewFileStore(config.GetStateFile())
+	default:
+		return nil, fmt.Errorf("unknown state store %q", config.GetStateStore())
+	}
+}
+
+// Ограничение частоты обработки сообщений и нажатий кнопок одного пользователя
+const (
+	userRateLimit = rate.Limit(3) // В секунду
+	userRateBurst = 10
+)
+
+// commandScopes области меню команд по ключам обработчиков: команды состояния Start видны
+// в личных чатах, список команд - еще и в чатах администраторов
+func commandScopes(chats []int64) tg.CommandScopes {
+	private := tgbotapi.NewBotCommandScopeAllPrivateChats()
+	scopes := tg.CommandScopes{}
+	for key := range Start.MessageHandlers {
+		if strings.HasPrefix(key, "/") {
+			scopes[key] = []tgbotapi.BotCommandScope{private}
+		}
+	}
+	for _, chatID := range chats {
+		scopes["/help"] = append(scopes["/help"], tgbotapi.NewBotCommandScopeChat(chatID))
+	}
+	return scopes
 }
 
 // updateHandler обработчик, который вызывается для каждого обновления
 func updateHandler() tg.HandlerFunc {
 	return func(b *tg.Bot, u tgbotapi.Update) error {
+		if err := handleReplies(b, u); err != nil {
+			logger.Log.Error().Err(err).Msg("Ошибка обработки ответа по заявке")
+		}
 		return nil
 	}
 }
 
-func InitBot(config Config, newForms chan *model.Request) {
+func InitBot(config Config, service service.Servicer, db *sql.DB) {
+	srv = service
+	adminChats = *config.GetMessageChats()
+	webAppURL = config.GetWebAppURL()
+
+	store, err := newStateStore(config, db)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Ошибка инициализации хранилища состояний")
+	}
 
 	bot, err := tg.NewBot(tg.Config{
 		Token:           config.GetToken(),
 		Expiration:      config.GetExpiration(),
 		CleanupInterval: config.GetCleanupInterval(),
+		Store:           store,
 		States:          states,
 		Logger:          &logger.Log,
 		UpdateHandler:   updateHandler(),
+		Mode:            tg.UpdateMode(config.GetUpdateMode()),
+		Webhook: tg.WebhookConfig{
+			URL:         config.GetWebhookURL(),
+			SecretToken: config.GetWebhookSecret(),
+		},
+		Throttle: tg.ThrottleConfig{
+			Limit: userRateLimit,
+			Burst: userRateBurst,
+		},
+		Dispatcher: tg.DispatcherConfig{
+			Workers:   config.GetWorkers(),
+			QueueSize: config.GetQueueSize(),
+			Overflow:  tg.OverflowPolicy(config.GetQueueOverflow()),
+		},
+		CommandScopes: commandScopes(adminChats),
 	})
 	if err != nil {
 		logger.Log.Fatal().Err(err).Msg("Ошибка инициализации бота")
 	}
 	Bot = bot
 
-	go sendForm(config.GetMessageChats(), newForms)
+	go sendForms(config.GetMessageChats(), srv.GetMessageChan(), srv.GetFormChangeChan())
 }
 
-func sendForm(chats *[]int64, newForms chan *model.Request) {
-	for request := range newForms {
-		message := formatMessage(request)
-		for _, chatID := range *chats {
-			msg := tgbotapi.MessageConfig{
-				BaseChat: tgbotapi.BaseChat{
-					ChatID: chatID,
-				},
-				Text: message,
-			}
-			msg.ParseMode = tgbotapi.ModeMarkdownV2
+// Run проверяет доступность чатов администраторов, регистрирует меню команд,
+// запускает обработку обновлений бота и блокируется до отмены ctx
+func Run(ctx context.Context) error {
+	// Недоступные чаты только записываются в лог, уведомления в остальные чаты продолжат работать
+	if err := Bot.ResolveChats(ctx, adminChats...); err != nil {
+		logger.Log.Warn().Msg("Не все чаты для уведомлений о заявках доступны")
+	}
+	// Меню команд регистрируется после проверки чатов, чтобы использовать найденные ID
+	if err := Bot.RegisterCommands(ctx); err != nil {
+		logger.Log.Error().Err(err).Msg("Ошибка регистрации меню команд")
+	}
+	return Bot.Run(ctx)
+}
+
+// Shutdown останавливает бота: прекращает получение обновлений, ждет завершения
+// обработчиков и отправляет уведомления о заявках, оставшиеся в очереди.
+// Ожидание ограничено дедлайном ctx. Перед вызовом нужно остановить HTTP сервер,
+// чтобы в очередь не попадали новые заявки.
+func Shutdown(ctx context.Context) error {
+	if Bot == nil {
+		return nil
+	}
+
+	botErr := Bot.Stop(ctx)
+
+	stopNotifier()
+	select {
+	case <-notifierDone:
+		logger.Log.Info().Msg("Очередь уведомлений о заявках отправлена")
+	case <-ctx.Done():
+		return errors.Join(botErr, fmt.Errorf("не успели отправить %d уведомлений о заявках: %w",
+			len(srv.GetMessageChan()), ctx.Err()))
+	}
+
+	return botErr
+}
 
-			_, err := Bot.SendMessage(msg)
-			if err != nil {
-				logger.Log.Error().Err(err).Msg("Ошибка отправки сообщения")
+// stopNotifier останавливает рассылку уведомлений. Безопасен при повторном вызове,
+// например когда Shutdown повторяется после истечения дедлайна.
+func stopNotifier() {
+	notifierStopOnce.Do(func() { close(notifierStop) })
+}
+
+// sendForms отправляет уведомления о новых заявках и обновляет уведомления о заявках,
+// измененных пользователем, до остановки бота, после чего обрабатывает оставшееся в очередях
+func sendForms(chats *[]int64, newForms chan *model.Request, changed chan int64) {
+	defer close(notifierDone)
+
+	for {
+		select {
+		case request := <-newForms:
+			sendForm(chats, request)
+		case formID := <-changed:
+			// Уведомление о заявке могло еще ждать в очереди новых заявок
+			sendPendingForms(chats, newForms)
+			refreshNotifications(formID)
+		case <-notifierStop:
+			sendPendingForms(chats, newForms)
+			for {
+				select {
+				case formID := <-changed:
+					refreshNotifications(formID)
+				default:
+					return
+				}
 			}
 		}
 	}
 }
 
+// sendPendingForms отправляет уведомления о заявках, уже находящихся в очереди, не дожидаясь новых
+func sendPendingForms(chats *[]int64, newForms chan *model.Request) {
+	for {
+		select {
+		case request := <-newForms:
+			sendForm(chats, request)
+		default:
+			return
+		}
+	}
+}
+
+// sendForm отправляет уведомление о заявке во все чаты администраторов
+func sendForm(chats *[]int64, request *model.Request) {
+	message := formatMessage(request)
+	for _, chatID := range *chats {
+		msg := tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{
+				ChatID: chatID,
+			},
+			Text: message,
+		}
+		msg.ParseMode = tgbotapi.ModeMarkdownV2
+		if keyboard := formKeyboard(&request.Form); keyboard != nil {
+			msg.ReplyMarkup = *keyboard
+		}
+
+		// ID чата из конфигурации может быть указан без префикса супергруппы
+		_, sended, err := Bot.SendMessageUnkownChatIdD(msg)
+		if err != nil {
+			logger.Log.Error().Err(err).Msg("Ошибка отправки сообщения")
+			continue
+		}
+
+		// Запоминаем уведомление, чтобы обновлять его при изменении заявки
+		err = srv.AddFormNotification(&model.FormNotification{
+			ChatID:    sended.Chat.ID,
+			MessageID: sended.MessageID,
+			FormID:    request.Form.ID.ID,
+		})
+		if err != nil {
+			logger.Log.Error().
+				Err(err).
+				Int64("form_id", request.Form.ID.ID).
+				Msg("Ошибка сохранения уведомления о заявке")
+		}
+	}
+}
+
 func formatMessage(request *model.Request) string {
 	var builder strings.Builder
 
 	// Заголовок
-	builder.WriteString("📝 *Новая заявка*\n\n")
+	builder.WriteString(fmt.Sprintf("📝 *Заявка №%d*\n\n", request.Form.ID.ID))
 
 	// Информация о пользователе
-	builder.WriteString(fmt.Sprintf("👤 *От:* %s", request.User.FirstName))
-	if request.User.LastName != "" {
-		builder.WriteString(" " + request.User.LastName)
-	}
-	if request.User.UserName != "" {
-		builder.WriteString(fmt.Sprintf(" (@%s)", request.User.UserName))
-	}
-	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf("👤 *От:* %s\n\n", formatUser(&request.User)))
 
 	// Информация из формы
-	builder.WriteString(fmt.Sprintf("📋 *Имя:* %s\n", request.Form.Name))
+	builder.WriteString(fmt.Sprintf("📋 *Имя:* %s\n", escape(request.Form.Name)))
 
 	// Добавляем способ обратной связи только если он указан
 	if request.Form.Feedback != "" {
-		builder.WriteString(fmt.Sprintf("📞 *Способ связи:* %s\n", request.Form.Feedback))
+		builder.WriteString(fmt.Sprintf("📞 *Способ связи:* %s\n", escape(request.Form.Feedback)))
 	}
 
 	// Добавляем комментарий только если он есть
 	if request.Form.Comment != "" {
-		builder.WriteString(fmt.Sprintf("\n💬 *Комментарий:*\n%s\n", request.Form.Comment))
+		builder.WriteString(fmt.Sprintf("\n💬 *Комментарий:*\n%s\n", escape(request.Form.Comment)))
 	}
 
-	// Добавляем время создания
-	builder.WriteString(fmt.Sprintf("\n🕐 *Время:* %s", request.Form.UpdatedAt.UpdatedAt.Format("02.01.2006 15:04")))
+	// Источник трафика, если пользователь пришел по ссылке start=src_<campaign>
+	if request.Form.Source != "" {
+		builder.WriteString(fmt.Sprintf("\n🔗 *Источник:* %s", escape(request.Form.Source)))
+	}
+
+	// Добавляем время последнего изменения
+	builder.WriteString(fmt.Sprintf("\n🕐 *Время:* %s", escape(request.Form.UpdatedAt.UpdatedAt.Format("02.01.2006 15:04"))))
+
+	// Статус и ответственный оператор
+	builder.WriteString(fmt.Sprintf("\n📌 *Статус:* %s", escape(statusLabel(request.Form.Status))))
+	if request.Assignee != nil {
+		builder.WriteString(fmt.Sprintf("\n🧑‍💼 *Ответственный:* %s", formatUser(request.Assignee)))
+	}
 
 	return builder.String()
 }
+
+// formatUser форматирует имя пользователя Telegram для MarkdownV2
+func formatUser(user *model.User) string {
+	name := escape(user.FirstName)
+	if user.LastName != "" {
+		name += " " + escape(user.LastName)
+	}
+	if user.UserName != "" {
+		name += fmt.Sprintf(" \\(@%s\\)", escape(user.UserName))
+	}
+	return name
+}
+
+// escape экранирует текст для MarkdownV2
+func escape(text string) string {
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdownV2, text)
+}
@@ -0,0 +1,59 @@
+package tg
+
+import (
+	"nstu/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestCommandScopes(t *testing.T) {
+	private := tgbotapi.NewBotCommandScopeAllPrivateChats()
+	scopes := commandScopes([]int64{-100, -200})
+
+	tests := []struct {
+		key  string
+		want []tgbotapi.BotCommandScope
+	}{
+		{key: "/start", want: []tgbotapi.BotCommandScope{private}},
+		{key: "/form", want: []tgbotapi.BotCommandScope{private}},
+		{key: "/cancel", want: []tgbotapi.BotCommandScope{private}},
+		{key: "/help", want: []tgbotapi.BotCommandScope{
+			private,
+			tgbotapi.NewBotCommandScopeChat(-100),
+			tgbotapi.NewBotCommandScopeChat(-200),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := scopes[tt.key]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scopes[%q] = %+v, want %+v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// Ключи - только команды, как в MessageHandlers, без имен состояний
+	for key := range scopes {
+		if _, ok := Start.MessageHandlers[key]; !ok || !strings.HasPrefix(key, "/") {
+			t.Errorf("unexpected scope key %q", key)
+		}
+	}
+}
+
+func TestStopNotifierTwice(t *testing.T) {
+	// Повторный Shutdown не должен паниковать на закрытии notifierStop
+	stopNotifier()
+	stopNotifier()
+
+	chats := []int64{}
+	go sendForms(&chats, make(chan *model.Request), make(chan int64))
+	select {
+	case <-notifierDone:
+	case <-time.After(time.Second):
+		t.Fatal("notifier did not stop")
+	}
+}
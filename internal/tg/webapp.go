@@ -0,0 +1,69 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"nstu/internal/model"
+	"nstu/pkg/tg"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webAppForm заявка, которую Mini App отправляет через Telegram.WebApp.sendData.
+// Формат совпадает с телом POST /api/v1/form.
+type webAppForm struct {
+	Name     string `json:"name"`
+	Feedback string `json:"feedback"`
+	Comment  string `json:"comment"`
+}
+
+// handleWebAppForm сохраняет заявку, отправленную из Mini App через sendData,
+// тем же путем, что и API. Работает без доступа Mini App к HTTP серверу.
+func handleWebAppForm(b *tg.Bot, u tgbotapi.Update) error {
+	msg := u.Message
+	if !msg.Chat.IsPrivate() || msg.From == nil {
+		return nil
+	}
+
+	data, err := tg.DecodeWebAppData[webAppForm](b, u)
+	if err != nil {
+		replyTo(b, msg, "Не удалось прочитать заявку из приложения, попробуйте еще раз")
+		if errors.Is(err, tg.ErrNoWebAppData) {
+			return err
+		}
+		return nil
+	}
+
+	form := &model.Form{
+		Name:     strings.TrimSpace(data.Name),
+		Feedback: strings.TrimSpace(data.Feedback),
+		Comment:  strings.TrimSpace(data.Comment),
+		Source:   formSource(b, msg.From.ID),
+	}
+	fields := []struct{ field, label, value string }{
+		{"Name", "Имя", form.Name},
+		{"Feedback", "Способ связи", form.Feedback},
+		{"Comment", "Комментарий", form.Comment},
+	}
+	for _, f := range fields {
+		if problem := formFieldProblem(f.field, f.value); problem != "" {
+			replyTo(b, msg, fmt.Sprintf("Заявка не отправлена. %s: %s", f.label, problem))
+			return nil
+		}
+	}
+
+	user := &model.User{
+		ID:        msg.From.ID,
+		FirstName: msg.From.FirstName,
+		LastName:  msg.From.LastName,
+		UserName:  msg.From.UserName,
+	}
+	if err := srv.CreateForm(user, form); err != nil {
+		replyTo(b, msg, "Не удалось отправить заявку, попробуйте позже")
+		return err
+	}
+
+	replyTo(b, msg, formSentText(b, form.ID.ID))
+	return nil
+}
@@ -0,0 +1,35 @@
+package tg
+
+import (
+	"encoding/json"
+	"nstu/internal/model"
+	"testing"
+)
+
+// Mini App отправляет через sendData то же тело, что и в POST /api/v1/form
+func TestWebAppFormFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "full", body: `{"name":"Иван","feedback":"@ivan","comment":"Не работает пропуск"}`},
+		{name: "without feedback", body: `{"name":"Иван","comment":"Вопрос по общежитию"}`},
+		{name: "extra fields", body: `{"name":"Иван","comment":"Вопрос","status":"resolved","source":"vk"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data webAppForm
+			if err := json.Unmarshal([]byte(tt.body), &data); err != nil {
+				t.Fatalf("decode web app form: %v", err)
+			}
+			var form model.Form
+			if err := json.Unmarshal([]byte(tt.body), &form); err != nil {
+				t.Fatalf("decode api form: %v", err)
+			}
+			if data.Name != form.Name || data.Feedback != form.Feedback || data.Comment != form.Comment {
+				t.Errorf("web app form %+v differs from api form %+v", data, form)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package tg
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	gocache "github.com/patrickmn/go-cache"
 	"github.com/rs/zerolog"
 )
 
@@ -15,22 +18,48 @@ import (
 type Config struct {
 	Token           string           // Токен бота
 	Expiration      time.Duration    // Время хранения состояний пользователя
-	CleanupInterval time.Duration    // Интервал очистки кеша
+	CleanupInterval time.Duration    // Интервал удаления истекших состояний
+	Store           StateStore       // Хранилище состояний пользователей. По умолчанию MemoryStore
 	States          map[string]State // Состояния пользователя
 	Logger          *zerolog.Logger  // Логгер для записи событий
 	UpdateHandler   HandlerFunc      // Обработчик, который будет вызываться при получении любого обновления
+	Mode            UpdateMode       // Способ получения обновлений. По умолчанию UpdateModePolling
+	Webhook         WebhookConfig    // Настройки webhook для UpdateModeWebhook
+	Dispatcher      DispatcherConfig // Настройки параллельной обработки обновлений
+	Middlewares     []Middleware     // Middleware для всех обработчиков состояний. nil - DefaultMiddlewares
+	Throttle        ThrottleConfig   // Ограничение частоты обновлений одного пользователя во всех состояниях
+	Retry           RetryPolicy      // Правила повтора запросов к API
+	Limits          LimiterConfig    // Ограничения частоты запросов к API
+	CommandScopes   CommandScopes    // Области меню команд из RegisterCommands
 }
 
 // Bot структура для бота
 type Bot struct {
-	BotAPI        *tgbotapi.BotAPI // API бота. Экспортируется для доступа к нему из вне
-	expiration    time.Duration    // Время хранения состояний пользователя
-	limiter       *Limiter         // Лимитер для ограничения количества запросов к API
-	cache         *gocache.Cache   // Кеш для хранения состояний пользователей
-	logger        *zerolog.Logger  // Логгер для записи событий
-	states        map[string]State // Состояния пользователя
-	globalStates  []*State         // Состояния, в которые может перейти пользователь из любого другоо
-	updateHandler HandlerFunc      // Обработчик, который будет вызываться при получении любого обновления
+	BotAPI        *tgbotapi.BotAPI             // API бота. Экспортируется для доступа к нему из вне
+	expiration    time.Duration                // Время хранения состояний пользователя
+	limiter       *Limiter                     // Лимитер для ограничения количества запросов к API
+	cleanupEvery  time.Duration                // Интервал удаления истекших состояний
+	store         StateStore                   // Хранилище состояний пользователей
+	entryLocks    [entryLockStripes]sync.Mutex // Блокировки изменения записей пользователей в store
+	logger        *zerolog.Logger              // Логгер для записи событий
+	states        map[string]State             // Состояния пользователя
+	globalStates  []*State                     // Состояния, в которые может перейти пользователь из любого другоо
+	updateHandler HandlerFunc                  // Обработчик, который будет вызываться при получении любого обновления
+	mode          UpdateMode                   // Способ получения обновлений
+	webhook       WebhookConfig                // Настройки webhook
+	updates       chan tgbotapi.Update         // Очередь полученных обновлений
+	stop          chan struct{}                // Закрывается при остановке бота
+	stopOnce      sync.Once                    // Защищает от повторной остановки
+	running       atomic.Bool                  // true после запуска Run
+	loopDone      chan struct{}                // Закрывается после выхода из цикла Run
+	inflight      sync.WaitGroup               // Запущенные обработчики обновлений
+	dispatcher    DispatcherConfig             // Настройки параллельной обработки обновлений
+	queues        []chan tgbotapi.Update       // Очереди обработчиков обновлений
+	middlewares   []Middleware                 // Middleware для всех обработчиков состояний
+	retryPolicy   RetryPolicy                  // Правила повтора запросов к API
+	webAppData    sync.Map                     // Данные Mini App необработанных обновлений по ID обновления
+	chatIDs       sync.Map                     // Найденные ID чатов по ID из конфигурации
+	commandScopes CommandScopes                // Области меню команд
 }
 
 // Конструктор нового бота
@@ -38,6 +67,9 @@ func NewBot(config Config) (*Bot, error) {
 	if config.States == nil {
 		return nil, ErrStatesNil
 	}
+	if err := validateStates(config.States); err != nil {
+		return nil, err
+	}
 	if config.Expiration < 0 {
 		return nil, NewValidationError(ErrNegativeExpiration, config.Expiration)
 	}
@@ -47,107 +79,249 @@ func NewBot(config Config) (*Bot, error) {
 	if config.Token == "" {
 		return nil, ErrInvalidToken
 	}
+	if config.Mode == "" {
+		config.Mode = UpdateModePolling
+	}
+	switch config.Mode {
+	case UpdateModePolling:
+	case UpdateModeWebhook:
+		if err := config.Webhook.validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, NewValidationError(ErrUnknownUpdateMode, config.Mode)
+	}
+
+	dispatcher, err := config.Dispatcher.withDefaults()
+	if err != nil {
+		return nil, err
+	}
 
 	botAPI, err := tgbotapi.NewBotAPI(config.Token)
 	if err != nil {
 		return nil, fmt.Errorf("не удается инициализировать бота telegram: %v", err)
 	}
 
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore(config.CleanupInterval)
+	}
+
+	// Глобальные состояния проверяются по алфавиту, чтобы порядок не зависел от обхода карты
 	globalStates := make([]*State, 0)
-	for _, state := range config.States {
-		if state.Global {
+	for _, name := range sortedStateNames(config.States) {
+		if state := config.States[name]; state.Global {
 			globalStates = append(globalStates, &state)
 		}
 	}
 
 	app := Bot{
 		BotAPI:        botAPI,
-		limiter:       NewLimiter(),
-		cache:         gocache.New(config.Expiration, config.CleanupInterval),
+		limiter:       NewLimiter(config.Limits),
+		cleanupEvery:  config.CleanupInterval,
+		store:         store,
 		states:        config.States,
 		globalStates:  globalStates,
 		expiration:    config.Expiration,
 		logger:        config.Logger,
 		updateHandler: config.UpdateHandler,
+		mode:          config.Mode,
+		webhook:       config.Webhook,
+		updates:       make(chan tgbotapi.Update, botAPI.Buffer),
+		stop:          make(chan struct{}),
+		loopDone:      make(chan struct{}),
+		dispatcher:    dispatcher,
+		queues:        newQueues(dispatcher),
+		middlewares:   botMiddlewares(config),
+		retryPolicy:   config.Retry.withDefaults(),
+		commandScopes: config.CommandScopes,
 	}
 
-	go app.HandleUpdates()
-
 	return &app, nil
 }
 
-// HandleUpdates запускает обработку всех обновлений поступающих боту из телеграмма
-func (app *Bot) HandleUpdates() {
-	// Настройка обновлений
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 30
-	updates := app.BotAPI.GetUpdatesChan(u)
-	app.logger.Info().Msg("Запуск обработки обновлений")
-	for update := range updates {
-
-		go func() {
-			if app.updateHandler != nil {
-				app.updateHandler(app, update)
-			}
-		}()
+// botMiddlewares возвращает middleware для всех обработчиков: Config.Middlewares
+// или DefaultMiddlewares, перед ними Throttle по Config.Throttle
+func botMiddlewares(config Config) []Middleware {
+	middlewares := config.Middlewares
+	if middlewares == nil {
+		middlewares = DefaultMiddlewares()
+	}
+	if config.Throttle.Limit > 0 {
+		middlewares = append([]Middleware{Throttle(config.Throttle.Limit, config.Throttle.Burst)}, middlewares...)
+	}
+	return middlewares
+}
 
-		go func(update tgbotapi.Update) {
+// Run запускает получение и обработку обновлений и блокируется до отмены ctx или вызова Stop.
+// В режиме webhook регистрирует webhook в Telegram, в режиме polling запускает getUpdates.
+func (app *Bot) Run(ctx context.Context) error {
+	if !app.running.CompareAndSwap(false, true) {
+		return ErrAlreadyRunning
+	}
+	defer close(app.loopDone)
 
-			// Обработка локальных стейтов
-			if update.SentFrom() == nil {
-				return
-			}
+	var updates <-chan tgbotapi.Update
+	switch app.mode {
+	case UpdateModeWebhook:
+		if err := app.setWebhook(ctx); err != nil {
+			return err
+		}
+		updates = app.updates
+	default:
+		// getUpdates не работает, пока установлен webhook
+		if err := app.deleteWebhook(ctx); err != nil {
+			app.logger.Error().Err(err).Msg("failed to delete webhook before polling")
+		}
+		go app.poll(ctx)
+		updates = app.updates
+	}
 
-			// Обработка глобальных стейтов
-			globalStateFound, err := app.HandleGlobalStates(update)
-			if err != nil {
-				app.logger.Error().Err(err).Msg("failed to handle global state")
-			}
-			// Если глобальное состояние найдено, то выходим из функции
-			if globalStateFound {
-				return
-			}
-			// Получение названия состояния пользователя
-			userStateName, err := app.GetUserState(update.SentFrom().ID)
-			if err != nil {
-				app.logger.Error().Err(err).Msg("failed to get user state")
-			}
-			// Получени состояния
-			userState, ok := app.states[userStateName]
-			if !ok {
-				app.logger.Debug().Str("state", userStateName).Msg("state not found in states map")
-			}
+	if store, ok := app.store.(ExpiringStore); ok && app.cleanupEvery > 0 {
+		go app.runCleanup(ctx, store)
+	}
 
-			// Выбор обработчика состояния
-			_, err = app.SelectHandler(update, &userState)
-			if err != nil {
-				app.logger.Error().Err(err).Msg("failed to handle user state")
+	app.startWorkers()
+	defer app.closeQueues()
+
+	app.logger.Info().Str("mode", string(app.mode)).Msg("Запуск обработки обновлений")
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-app.stop:
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
 			}
+			app.dispatch(ctx, update)
+		}
+	}
+}
 
-		}(update)
+// Stop прекращает получение обновлений и ждет, пока обработчики закончат обновления,
+// уже попавшие в очереди, но не дольше дедлайна ctx. В режиме webhook удаляет webhook в Telegram.
+func (app *Bot) Stop(ctx context.Context) error {
+	var err error
+	app.stopOnce.Do(func() {
+		close(app.stop)
+		if app.mode == UpdateModeWebhook {
+			err = app.deleteWebhook(ctx)
+		}
+	})
+
+	// Новые обработчики не запускаются после выхода из цикла Run
+	if app.running.Load() {
+		select {
+		case <-app.loopDone:
+		case <-ctx.Done():
+			return fmt.Errorf("не дождались остановки получения обновлений: %w", ctx.Err())
+		}
+	}
 
+	handlersDone := make(chan struct{})
+	go func() {
+		app.inflight.Wait()
+		close(handlersDone)
+	}()
+	select {
+	case <-handlersDone:
+		app.logger.Info().Msg("Обработка обновлений остановлена")
+	case <-ctx.Done():
+		return fmt.Errorf("не дождались завершения обработчиков обновлений: %w", ctx.Err())
 	}
+
+	return err
 }
 
-func (app *Bot) GetUserState(userId int64) (string, error) {
-	userStateInterface, ok := app.cache.Get(strconv.FormatInt(userId, 10))
-	if !ok {
-		return "", fmt.Errorf("пользовательский статус не найден") // Обработка ошибки
+// processUpdate обрабатывает одно обновление: вызывает общий обработчик,
+// затем обработчики глобальных состояний и состояния пользователя
+func (app *Bot) processUpdate(update tgbotapi.Update) {
+	defer app.forgetUpdate(update)
+	// Паника вне middleware Recover не должна останавливать обработчик очереди
+	defer func() {
+		if r := recover(); r != nil {
+			app.logger.Error().
+				Int("update_id", update.UpdateID).
+				Str("stack", string(debug.Stack())).
+				Msgf("panic while processing update: %v", r)
+		}
+	}()
+
+	if app.updateHandler != nil {
+		if err := app.updateHandler(app, update); err != nil {
+			app.logger.Error().Err(err).Int("update_id", update.UpdateID).Msg("failed to handle update")
+		}
 	}
 
-	userState, ok := userStateInterface.(string)
+	// Обработка локальных стейтов
+	user := UpdateUser(update)
+	if user == nil {
+		return
+	}
+
+	// Обработка глобальных стейтов
+	globalStateFound, err := app.HandleGlobalStates(update)
+	if err != nil {
+		app.logger.Error().Err(err).Msg("failed to handle global state")
+	}
+	// Если глобальное состояние найдено, то выходим из функции
+	if globalStateFound {
+		return
+	}
+	// Получение названия состояния пользователя
+	userStateName, err := app.GetUserState(user.ID)
+	if err != nil && !errors.Is(err, ErrStateNotFound) {
+		app.logger.Error().Err(err).Msg("failed to get user state")
+	}
+	// Получени состояния
+	userState, ok := app.states[userStateName]
 	if !ok {
-		return "", fmt.Errorf("пользовательский статус не найден") // Обработка ошибки
+		app.logger.Debug().Str("state", userStateName).Msg("state not found in states map")
 	}
 
-	return userState, nil // Возврат состояния пользователя
+	// Выбор обработчика состояния
+	_, err = app.SelectHandler(update, &userState)
+	if err != nil {
+		app.logger.Error().Err(err).Msg("failed to handle user state")
+	}
+}
+
+// runCleanup периодически удаляет истекшие состояния до остановки бота
+func (app *Bot) runCleanup(ctx context.Context, store ExpiringStore) {
+	ticker := time.NewTicker(app.cleanupEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-app.stop:
+			return
+		case <-ticker.C:
+			if err := store.DeleteExpired(); err != nil {
+				app.logger.Error().Err(err).Msg("failed to delete expired user states")
+			}
+		}
+	}
+}
+
+// GetUserState возвращает состояние пользователя или ErrStateNotFound
+func (app *Bot) GetUserState(userId int64) (string, error) {
+	entry, err := app.store.Get(userId)
+	if err != nil {
+		return "", err
+	}
+	if entry.State == "" {
+		return "", ErrStateNotFound
+	}
+	return entry.State, nil
 }
 
 // SetUserState меняет состояние пользователя
 // immediate - если true, то новое состояние применится сразу к текущему сообщению
 func (app *Bot) SetUserState(userId int64, state string, immediate bool, update *tgbotapi.Update) {
-	key := strconv.FormatInt(userId, 10)
-
 	st, ok := app.states[state]
 	if !ok {
 		app.logger.Error().Str("state", state).Msg("state not found")
@@ -157,12 +331,19 @@ func (app *Bot) SetUserState(userId int64, state string, immediate bool, update
 		return
 	}
 
-	app.cache.Set(key, state, app.expiration)
+	// Данные сессии сохраняются при смене состояния
+	err := app.updateEntry(userId, func(entry *StateEntry) {
+		entry.State = state
+	})
+	if err != nil {
+		app.logger.Error().Err(err).Str("state", state).Msg("failed to save user state")
+		return
+	}
 
 	if newState, ok := app.states[state]; ok {
 		// Вызываем действие при входе, если оно есть и это не глобальное состояние
 		if newState.AtEntranceFunc != nil && !newState.Global && update != nil {
-			if err := newState.AtEntranceFunc.Handle(app, *update); err != nil {
+			if err := app.wrap(&newState, newState.AtEntranceFunc.Handle)(app, *update); err != nil {
 				app.logger.Error().
 					Err(err).
 					Str("state", state).
@@ -191,12 +372,10 @@ func (app *Bot) HandleGlobalStates(update tgbotapi.Update) (bool, error) {
 	for _, state := range app.globalStates {
 		// Обработка состояния
 		handlerIsFound, err := app.SelectHandler(update, state)
-		// Если ошибка, то пропускаем состояние
 		if err != nil {
 			app.logger.Error().Err(err).Msg("failed to handle global state")
-			continue
 		}
-		// Если обработчик найден, то возвращаем true
+		// Если обработчик найден, то возвращаем true, даже если он завершился ошибкой
 		if handlerIsFound {
 			return true, nil
 		}
@@ -205,9 +384,13 @@ func (app *Bot) HandleGlobalStates(update tgbotapi.Update) (bool, error) {
 }
 
 func (app *Bot) SelectHandler(update tgbotapi.Update, userState *State) (bool, error) {
+	if handler := userState.kindHandler(app, update); handler != nil {
+		return true, app.wrap(userState, handler.Handle)(app, update)
+	}
+
 	switch {
 	case update.Message != nil:
-		if userState.MessageHandlers != nil {
+		if userState.MessageHandlers != nil || userState.MessageRoutes != nil || userState.CatchAllFunc != nil {
 			return app.handleMessage(userState, update)
 		} else {
 			app.logger.Info().
@@ -218,7 +401,7 @@ func (app *Bot) SelectHandler(update tgbotapi.Update, userState *State) (bool, e
 			return false, nil
 		}
 	case update.CallbackQuery != nil:
-		if userState.CallbackHandlers != nil {
+		if userState.CallbackHandlers != nil || userState.CallbackRoutes != nil || userState.CatchAllFunc != nil {
 			return app.handleCallback(userState, update)
 		} else {
 			app.logger.Info().
@@ -234,149 +417,78 @@ func (app *Bot) SelectHandler(update tgbotapi.Update, userState *State) (bool, e
 
 // handleMessage ищет команду в map'е и выполняет ее
 func (app *Bot) handleMessage(userState *State, update tgbotapi.Update) (bool, error) {
-	messageFound := false
-
 	if currentAction, ok := userState.MessageHandlers[strings.ToLower(strings.TrimSpace(update.Message.Text))]; ok {
-		messageFound = true
-		if err := currentAction.Handle(app, update); err != nil {
-			app.logger.Error().
-				Err(err).
-				Str("command", update.Message.Text).
-				Int64("chat_id", update.Message.Chat.ID).
-				Str("username", update.Message.Chat.UserName).
-				Msg("failed to handle command")
-		} else {
-			app.logger.Info().
-				Str("command", update.Message.Text).
-				Int64("chat_id", update.Message.Chat.ID).
-				Str("username", update.Message.Chat.UserName).
-				Msg("command handled successfully")
-		}
-	} else {
-		if userState.CatchAllFunc != nil {
-			err := userState.CatchAllFunc.Handle(app, update)
-			if err != nil {
-				app.logger.Error().
-					Err(err).
-					Int64("chat_id", update.Message.Chat.ID).
-					Str("username", update.Message.Chat.UserName).
-					Str("command", update.Message.Text).
-					Msg("failed to handle command")
-			}
-		} else {
-			app.logger.Info().
-				Int64("chat_id", update.Message.Chat.ID).
-				Str("username", update.Message.Chat.UserName).
-				Str("command", update.Message.Text).
-				Msg("command not found")
-		}
-
+		return true, app.wrap(userState, currentAction.Handle)(app, update)
 	}
-	return messageFound, nil
-}
 
-// handleCallback ищет команду в map'е и выполняет ее
-func (app *Bot) handleCallback(userState *State, update tgbotapi.Update) (bool, error) {
-	callbackFound := false
+	if route, params, ok := matchRoute(userState.MessageRoutes, update.Message.Text); ok {
+		return true, app.handleRoute(userState, route, params, update)
+	}
 
-	if currentAction, ok := userState.CallbackHandlers[update.CallbackQuery.Data]; ok {
-		callbackFound = true
-		if err := currentAction.Handle(app, update); err != nil {
-			app.logger.Error().
-				Err(err).
-				Str("callback", update.CallbackQuery.Data).
-				Int64("user_id", update.CallbackQuery.From.ID).
-				Str("username", update.CallbackQuery.From.UserName).
-				Msg("failed to handle callback")
-			return callbackFound, err
+	// Ссылка t.me/bot?start=payload приходит как "/start payload". Без подходящего маршрута DeepLink
+	// она обрабатывается обработчиком "/start", данные доступны через StartPayload
+	if StartPayload(update) != "" {
+		if currentAction, ok := userState.MessageHandlers[startCommand]; ok {
+			return true, app.wrap(userState, currentAction.Handle)(app, update)
 		}
+	}
 
-		app.logger.Info().
-			Str("callback", update.CallbackQuery.Data).
-			Int64("user_id", update.CallbackQuery.From.ID).
-			Str("username", update.CallbackQuery.From.UserName).
-			Msg("callback handled successfully")
-	} else {
-		if userState.CatchAllFunc != nil {
-			err := userState.CatchAllFunc.Handle(app, update)
-			if err != nil {
-				app.logger.Error().
-					Err(err).
-					Int64("user_id", update.CallbackQuery.From.ID).
-					Str("username", update.CallbackQuery.From.UserName).
-					Str("callback", update.CallbackQuery.Data).
-					Msg("failed to handle callback")
-				return callbackFound, err
-			}
-		} else {
-			app.logger.Info().
-				Int64("user_id", update.CallbackQuery.From.ID).
-				Str("username", update.CallbackQuery.From.UserName).
-				Str("callback", update.CallbackQuery.Data).
-				Msg("callback not found")
-		}
+	if userState.CatchAllFunc != nil {
+		return false, app.wrap(userState, userState.CatchAllFunc.Handle)(app, update)
 	}
-	return callbackFound, nil
+
+	app.logger.Info().
+		Int64("chat_id", update.Message.Chat.ID).
+		Str("username", update.Message.Chat.UserName).
+		Str("command", update.Message.Text).
+		Msg("command not found")
+	return false, nil
 }
 
-// CheckMessage проверяет возможность отправки сообщения в чат
-func (b *Bot) CheckMessage(chatID int64) {
-	b.limiter.mu.Lock()
-	defer b.limiter.mu.Unlock()
+// handleCallback ищет команду в map'е и выполняет ее
+func (app *Bot) handleCallback(userState *State, update tgbotapi.Update) (bool, error) {
+	currentAction, ok := userState.CallbackHandlers[update.CallbackQuery.Data]
+	if !ok {
+		// Данные вида "key:arg1:arg2" обрабатываются обработчиком "key"
+		currentAction, ok = userState.CallbackHandlers[CallbackKey(update.CallbackQuery.Data)]
+	}
 
-	now := time.Now()
+	if ok {
+		return true, app.wrap(userState, currentAction.Handle)(app, update)
+	}
 
-	if lastTime, ok := b.limiter.ChatTimes[chatID]; ok {
-		if diff := time.Second - now.Sub(lastTime); diff > 0 {
-			time.Sleep(diff)
-			now = time.Now()
-		}
+	if route, params, ok := matchRoute(userState.CallbackRoutes, update.CallbackQuery.Data); ok {
+		return true, app.handleRoute(userState, route, params, update)
 	}
 
-	b.cleanup(now)
-	if len(b.limiter.MessageTimes) >= MultiChatLimit || len(b.limiter.ApiTimes) >= GlobalLimit {
-		time.Sleep(WaitTime)
-		now = time.Now()
-		b.cleanup(now)
+	if userState.CatchAllFunc != nil {
+		return false, app.wrap(userState, userState.CatchAllFunc.Handle)(app, update)
 	}
 
-	b.limiter.ChatTimes[chatID] = now
-	b.limiter.MessageTimes = append(b.limiter.MessageTimes, now)
-	b.limiter.ApiTimes = append(b.limiter.ApiTimes, now)
+	app.logger.Info().
+		Int64("user_id", update.CallbackQuery.From.ID).
+		Str("username", update.CallbackQuery.From.UserName).
+		Str("callback", update.CallbackQuery.Data).
+		Msg("callback not found")
+	return false, nil
 }
 
-// CheckAPI проверяет возможность отправки запроса к API
-func (b *Bot) CheckAPI() {
-	b.limiter.mu.Lock()
-	defer b.limiter.mu.Unlock()
-
-	now := time.Now()
-	b.cleanup(now)
-
-	if len(b.limiter.ApiTimes) >= GlobalLimit {
-		time.Sleep(WaitTime)
-		now = time.Now()
-		b.cleanup(now)
+// CheckMessage ждет возможности отправить сообщение в чат chatID.
+// Нужен только для запросов напрямую через BotAPI, Send и Request ждут сами.
+func (b *Bot) CheckMessage(chatID int64) {
+	if err := b.limiter.Wait(context.Background(), chatID); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("failed to wait for rate limiter")
 	}
-
-	b.limiter.ApiTimes = append(b.limiter.ApiTimes, now)
 }
 
-// cleanup удаляет записи старше 1 секунды
-func (b *Bot) cleanup(now time.Time) {
-	newMessageTimes := b.limiter.MessageTimes[:0]
-	for _, t := range b.limiter.MessageTimes {
-		if now.Sub(t) < time.Second {
-			newMessageTimes = append(newMessageTimes, t)
-		}
+// CheckAPI ждет возможности отправить запрос к API напрямую через BotAPI
+func (b *Bot) CheckAPI() {
+	if err := b.limiter.WaitAPI(context.Background()); err != nil {
+		b.logger.Error().Err(err).Msg("failed to wait for rate limiter")
 	}
-	b.limiter.MessageTimes = newMessageTimes
+}
 
-	newAPITimes := b.limiter.ApiTimes[:0]
-	for _, t := range b.limiter.ApiTimes {
-		if now.Sub(t) < time.Second {
-			newAPITimes = append(newAPITimes, t)
-		}
-	}
-	b.limiter.ApiTimes = newAPITimes
+// Limiter возвращает лимитер запросов к API, например для мониторинга длины очереди
+func (b *Bot) Limiter() *Limiter {
+	return b.limiter
 }
@@ -0,0 +1,212 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestNewBotValidation(t *testing.T) {
+	states := map[string]State{"start": {Global: true}}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr error
+	}{
+		{name: "nil states", config: Config{Token: "123:token"}, wantErr: ErrStatesNil},
+		{name: "invalid states", config: Config{Token: "123:token", States: map[string]State{"start": {Global: true, Transitions: []string{"missing"}}}}, wantErr: ErrUnknownTransition},
+		{name: "negative expiration", config: Config{Token: "123:token", States: states, Expiration: -time.Second}, wantErr: ErrNegativeExpiration},
+		{name: "negative cleanup", config: Config{Token: "123:token", States: states, CleanupInterval: -time.Second}, wantErr: ErrNegativeCleanup},
+		{name: "empty token", config: Config{States: states}, wantErr: ErrInvalidToken},
+		{name: "unknown mode", config: Config{Token: "123:token", States: states, Mode: "push"}, wantErr: ErrUnknownUpdateMode},
+		{name: "webhook without url", config: Config{Token: "123:token", States: states, Mode: UpdateModeWebhook}, wantErr: ErrWebhookURLEmpty},
+		{name: "invalid webhook secret", config: Config{Token: "123:token", States: states, Mode: UpdateModeWebhook, Webhook: WebhookConfig{URL: "https://example.com/tg", SecretToken: "a b"}}, wantErr: ErrInvalidWebhookSecret},
+		{name: "negative workers", config: Config{Token: "123:token", States: states, Dispatcher: DispatcherConfig{Workers: -1}}, wantErr: ErrInvalidWorkers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBot(tt.config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewBot() error = %v, want %v", err, tt.wantErr)
+			}
+			if b != nil {
+				t.Error("NewBot() must not return a bot on error")
+			}
+		})
+	}
+}
+
+// lifecycleBot бот, который передает текст каждого сообщения в handled
+func lifecycleBot(t *testing.T, config Config) (*Bot, chan string) {
+	t.Helper()
+	handled := make(chan string, 10)
+	config.States = map[string]State{
+		"start": {
+			Global: true,
+			CatchAllFunc: &Handler{Handle: func(_ *Bot, u tgbotapi.Update) error {
+				handled <- u.Message.Text
+				return nil
+			}},
+		},
+	}
+	return newTestBot(t, config), handled
+}
+
+// runBot запускает Run в горутине и возвращает канал с его результатом
+func runBot(b *Bot, ctx context.Context) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+	return done
+}
+
+// waitHandled ждет обработки сообщения с текстом want
+func waitHandled(t *testing.T, handled <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-handled:
+		if got != want {
+			t.Errorf("handled %q, want %q", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("message %q was not handled", want)
+	}
+}
+
+// stopBot останавливает бота и проверяет результат Run
+func stopBot(t *testing.T, b *Bot, done <-chan error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Run() = %v, want nil after Stop", err)
+	}
+}
+
+func TestRunPolling(t *testing.T) {
+	b, handled := lifecycleBot(t, Config{})
+	sent := false
+	api := withFakeAPI(b, func(call apiCall) tgbotapi.APIResponse {
+		if call.method != "getUpdates" {
+			return okResponse(true)
+		}
+		if !sent {
+			sent = true
+			return okResponse([]tgbotapi.Update{textUpdate(7, 42, "hello")})
+		}
+		// Как long polling без новых обновлений
+		time.Sleep(10 * time.Millisecond)
+		return okResponse([]tgbotapi.Update{})
+	})
+
+	done := runBot(b, context.Background())
+	waitHandled(t, handled, "hello")
+	stopBot(t, b, done)
+
+	methods := api.methods()
+	if len(methods) < 2 || methods[0] != "deleteWebhook" || methods[1] != "getUpdates" {
+		t.Errorf("methods = %v, want deleteWebhook then getUpdates", methods)
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	b, handled := lifecycleBot(t, Config{
+		Mode:    UpdateModeWebhook,
+		Webhook: WebhookConfig{URL: "https://example.com/tg"},
+	})
+	api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	done := runBot(b, context.Background())
+	body := `{"update_id":3,"message":{"message_id":1,"from":{"id":42},"chat":{"id":42,"type":"private"},"text":"hello"}}`
+	rec := httptest.NewRecorder()
+	b.WebhookHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tg", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("webhook status = %d", rec.Code)
+	}
+	waitHandled(t, handled, "hello")
+	stopBot(t, b, done)
+
+	if methods := api.methods(); !slices.Equal(methods, []string{"setWebhook", "deleteWebhook"}) {
+		t.Errorf("methods = %v, want setWebhook then deleteWebhook", methods)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Run("already running", func(t *testing.T) {
+		b, _ := lifecycleBot(t, Config{})
+		b.running.Store(true)
+		if err := b.Run(context.Background()); !errors.Is(err, ErrAlreadyRunning) {
+			t.Errorf("Run() = %v, want %v", err, ErrAlreadyRunning)
+		}
+	})
+
+	t.Run("set webhook failed", func(t *testing.T) {
+		b, _ := lifecycleBot(t, Config{Mode: UpdateModeWebhook, Webhook: WebhookConfig{URL: "http://example.com/tg"}})
+		withFakeAPI(b, func(apiCall) tgbotapi.APIResponse {
+			return errorResponse(400, "Bad Request: bad webhook: HTTPS url must be provided for webhook", nil)
+		})
+		if err := b.Run(context.Background()); err == nil {
+			t.Error("Run() must fail when setWebhook fails")
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		b, _ := lifecycleBot(t, Config{Mode: UpdateModeWebhook, Webhook: WebhookConfig{URL: "https://example.com/tg"}})
+		withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+		ctx, cancel := context.WithCancel(context.Background())
+		done := runBot(b, ctx)
+		cancel()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Run() = %v, want %v", err, context.Canceled)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Run() did not return after cancel")
+		}
+	})
+}
+
+func TestStopWaitsForHandlers(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	b := newTestBot(t, Config{
+		Mode:    UpdateModeWebhook,
+		Webhook: WebhookConfig{URL: "https://example.com/tg"},
+		States: map[string]State{
+			"start": {
+				Global: true,
+				CatchAllFunc: &Handler{Handle: func(*Bot, tgbotapi.Update) error {
+					close(started)
+					<-release
+					return nil
+				}},
+			},
+		},
+	})
+	withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	done := runBot(b, context.Background())
+	b.updates <- textUpdate(1, 42, "slow")
+	<-started
+
+	// Обработчик еще работает: Stop возвращает ошибку по дедлайну
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := b.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	stopBot(t, b, done)
+}
@@ -0,0 +1,72 @@
+package tg
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// newTestBot создает бота так же, как NewBot, но без запроса getMe к Telegram.
+// Запросы к API обработчики выполняют через withFakeAPI.
+func newTestBot(t *testing.T, config Config) *Bot {
+	t.Helper()
+	if err := validateStates(config.States); err != nil {
+		t.Fatalf("invalid states: %v", err)
+	}
+	if config.Mode == "" {
+		config.Mode = UpdateModePolling
+	}
+	dispatcher, err := config.Dispatcher.withDefaults()
+	if err != nil {
+		t.Fatalf("invalid dispatcher config: %v", err)
+	}
+	store := config.Store
+	if store == nil {
+		store = NewMemoryStore(0)
+	}
+
+	logger := zerolog.Nop()
+	globalStates := make([]*State, 0)
+	for _, name := range sortedStateNames(config.States) {
+		if state := config.States[name]; state.Global {
+			globalStates = append(globalStates, &state)
+		}
+	}
+
+	return &Bot{
+		BotAPI:        &tgbotapi.BotAPI{Self: tgbotapi.User{ID: 1, UserName: "test_bot"}},
+		limiter:       NewLimiter(config.Limits),
+		cleanupEvery:  config.CleanupInterval,
+		store:         store,
+		states:        config.States,
+		globalStates:  globalStates,
+		expiration:    config.Expiration,
+		logger:        &logger,
+		updateHandler: config.UpdateHandler,
+		mode:          config.Mode,
+		webhook:       config.Webhook,
+		updates:       make(chan tgbotapi.Update, 100), // Как BotAPI.Buffer по умолчанию
+		stop:          make(chan struct{}),
+		loopDone:      make(chan struct{}),
+		dispatcher:    dispatcher,
+		queues:        newQueues(dispatcher),
+		middlewares:   botMiddlewares(config),
+		retryPolicy:   config.Retry.withDefaults(),
+		commandScopes: config.CommandScopes,
+	}
+}
+
+// textUpdate сообщение text от пользователя userID в личном чате
+func textUpdate(updateID int, userID int64, text string) tgbotapi.Update {
+	user := &tgbotapi.User{ID: userID, FirstName: "Ivan"}
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			MessageID: updateID,
+			From:      user,
+			Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+			Text:      text,
+		},
+	}
+}
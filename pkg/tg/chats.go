@@ -0,0 +1,206 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendMessageUnkownChatIdD отправляет сообщение в чат, ID которого указан без префикса
+// супергруппы или знака группы. При первой отправке перебирает варианты ID из chatIDCandidates
+// и запоминает подошедший, следующие сообщения сразу уходят по нему. Если группа стала
+// супергруппой, сообщение повторяется в новый чат, новый ID запоминается.
+// Возвращает ID чата, в который отправлено сообщение.
+func (app *Bot) SendMessageUnkownChatIdD(msg tgbotapi.MessageConfig) (int64, tgbotapi.Message, error) {
+	ctx := context.Background()
+	configured := msg.ChatID
+
+	if resolved, ok := app.chatIDs.Load(configured); ok {
+		msg.ChatID = resolved.(int64)
+		chatID, sended, err := app.sendFollowingMigration(ctx, msg)
+		if err != nil {
+			return chatID, sended, err
+		}
+		app.chatIDs.Store(configured, chatID)
+		return chatID, sended, nil
+	}
+
+	var err error
+	for _, candidate := range chatIDCandidates(configured) {
+		msg.ChatID = candidate
+
+		var chatID int64
+		var sended tgbotapi.Message
+		chatID, sended, err = app.sendFollowingMigration(ctx, msg)
+		if err == nil {
+			app.chatIDs.Store(configured, chatID)
+			app.logger.Info().
+				Int64("chat_id", configured).
+				Int64("resolved_chat_id", chatID).
+				Msg("chat id resolved")
+			return chatID, sended, nil
+		}
+		if !wrongChatID(err) {
+			break
+		}
+	}
+
+	return msg.ChatID, tgbotapi.Message{}, fmt.Errorf("failed all attempts to send message to chat %d: %w", configured, err)
+}
+
+// ResolveChatID возвращает ID чата, найденный для ID из конфигурации
+// в SendMessageUnkownChatIdD или ResolveChats. Если ID еще не проверялся, возвращает его без изменений.
+func (app *Bot) ResolveChatID(chatID int64) int64 {
+	if resolved, ok := app.chatIDs.Load(chatID); ok {
+		return resolved.(int64)
+	}
+	return chatID
+}
+
+// ResolveChats находит каждый чат через getChat, запоминает его настоящий ID
+// и проверяет, что бот может в него писать. Недоступные чаты и чаты без прав
+// записываются в лог, ошибки по всем чатам возвращаются вместе.
+func (app *Bot) ResolveChats(ctx context.Context, chatIDs ...int64) error {
+	var errs []error
+	for _, configured := range chatIDs {
+		chat, err := app.resolveChat(ctx, configured)
+		if err != nil {
+			app.logger.Warn().Int64("chat_id", configured).Err(err).Msg("chat is unreachable")
+			errs = append(errs, fmt.Errorf("chat %d: %w", configured, err))
+			continue
+		}
+		app.chatIDs.Store(configured, chat.ID)
+
+		if err := app.checkCanPost(ctx, chat); err != nil {
+			app.logger.Warn().
+				Int64("chat_id", configured).
+				Int64("resolved_chat_id", chat.ID).
+				Str("title", chat.Title).
+				Err(err).
+				Msg("bot cannot post to chat")
+			errs = append(errs, fmt.Errorf("chat %d: %w", configured, err))
+			continue
+		}
+
+		app.logger.Info().
+			Int64("chat_id", configured).
+			Int64("resolved_chat_id", chat.ID).
+			Str("title", chat.Title).
+			Msg("chat resolved")
+	}
+	return errors.Join(errs...)
+}
+
+// sendFollowingMigration отправляет сообщение и, если группа стала супергруппой,
+// повторяет отправку в новый чат. Возвращает ID чата, в который отправлено сообщение.
+func (app *Bot) sendFollowingMigration(ctx context.Context, msg tgbotapi.MessageConfig) (int64, tgbotapi.Message, error) {
+	sended, err := app.Send(ctx, msg)
+	var apiErr *APIError
+	if !errors.Is(err, ErrChatMigrated) || !errors.As(err, &apiErr) {
+		return msg.ChatID, sended, err
+	}
+
+	app.logger.Info().
+		Int64("chat_id", msg.ChatID).
+		Int64("new_chat_id", apiErr.MigrateToChatID).
+		Msg("chat migrated to supergroup")
+	msg.ChatID = apiErr.MigrateToChatID
+	sended, err = app.Send(ctx, msg)
+	return msg.ChatID, sended, err
+}
+
+// resolveChat ищет чат по вариантам ID с учетом перехода группы в супергруппу
+func (app *Bot) resolveChat(ctx context.Context, configured int64) (*tgbotapi.Chat, error) {
+	var err error
+	for _, candidate := range chatIDCandidates(configured) {
+		var chat *tgbotapi.Chat
+		chat, err = app.getChat(ctx, candidate)
+
+		var apiErr *APIError
+		if errors.Is(err, ErrChatMigrated) && errors.As(err, &apiErr) {
+			chat, err = app.getChat(ctx, apiErr.MigrateToChatID)
+		}
+		if err == nil {
+			return chat, nil
+		}
+		if !wrongChatID(err) {
+			break
+		}
+	}
+	return nil, err
+}
+
+// getChat запрашивает информацию о чате
+func (app *Bot) getChat(ctx context.Context, chatID int64) (*tgbotapi.Chat, error) {
+	resp, err := app.Request(ctx, tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatID}})
+	if err != nil {
+		return nil, err
+	}
+
+	var chat tgbotapi.Chat
+	if err := json.Unmarshal(resp.Result, &chat); err != nil {
+		return nil, fmt.Errorf("failed to decode chat: %w", err)
+	}
+	return &chat, nil
+}
+
+// checkCanPost проверяет, что бот состоит в группе или канале и может отправлять в него сообщения.
+// Для личных чатов проверка невозможна: о блокировке бота Telegram сообщает только при отправке.
+func (app *Bot) checkCanPost(ctx context.Context, chat *tgbotapi.Chat) error {
+	if chat.IsPrivate() {
+		return nil
+	}
+
+	resp, err := app.Request(ctx, tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: app.BotAPI.Self.ID},
+	})
+	if err != nil {
+		return err
+	}
+	var member tgbotapi.ChatMember
+	if err := json.Unmarshal(resp.Result, &member); err != nil {
+		return fmt.Errorf("failed to decode chat member: %w", err)
+	}
+
+	switch {
+	case member.HasLeft() || member.WasKicked():
+		return ErrBotNotMember
+	case member.IsCreator():
+		return nil
+	case chat.IsChannel():
+		if !member.IsAdministrator() || !member.CanPostMessages {
+			return ErrCannotPost
+		}
+	case member.IsAdministrator():
+		return nil
+	case member.Status == "restricted" && !member.CanSendMessages:
+		return ErrCannotPost
+	case chat.Permissions != nil && !chat.Permissions.CanSendMessages:
+		return ErrCannotPost
+	}
+	return nil
+}
+
+// chatIDCandidates варианты ID чата: как указан, супергруппа или канал с префиксом -100
+// и обычная группа со знаком минус. Отрицательный ID считается указанным полностью.
+func chatIDCandidates(chatID int64) []int64 {
+	if chatID <= 0 {
+		return []int64{chatID}
+	}
+	return []int64{chatID, addNegative100Prefix(chatID), -chatID}
+}
+
+// wrongChatID сообщает, что ошибка вызвана неверным вариантом ID и стоит попробовать следующий
+func wrongChatID(err error) bool {
+	return errors.Is(err, ErrChatNotFound) || errors.Is(err, ErrForbidden)
+}
+
+func addNegative100Prefix(num int64) int64 {
+	str := fmt.Sprintf("-100%d", num)
+	result, _ := strconv.ParseInt(str, 10, 64)
+	return result
+}
@@ -0,0 +1,265 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// apiCall запрос бота к Bot API
+type apiCall struct {
+	method string
+	params url.Values
+}
+
+// fakeAPI подменяет HTTP клиент tgbotapi: запросы записываются, ответ формирует handle
+type fakeAPI struct {
+	mu     sync.Mutex // Запросы могут идти из нескольких горутин, например из poll
+	calls  []apiCall
+	handle func(call apiCall) tgbotapi.APIResponse
+}
+
+// withFakeAPI направляет запросы бота в fakeAPI
+func withFakeAPI(b *Bot, handle func(call apiCall) tgbotapi.APIResponse) *fakeAPI {
+	api := &fakeAPI{handle: handle}
+	b.BotAPI.Client = api
+	b.BotAPI.SetAPIEndpoint(tgbotapi.APIEndpoint)
+	return api
+}
+
+func (f *fakeAPI) Do(req *http.Request) (*http.Response, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	call := apiCall{method: path.Base(req.URL.Path), params: req.PostForm}
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+
+	body, err := json.Marshal(f.handle(call))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+// methods методы всех запросов по порядку
+func (f *fakeAPI) methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	methods := make([]string, 0, len(f.calls))
+	for _, call := range f.calls {
+		methods = append(methods, call.method)
+	}
+	return methods
+}
+
+// chatIDs ID чатов из запросов method по порядку
+func (f *fakeAPI) chatIDs(method string) []int64 {
+	var ids []int64
+	for _, call := range f.calls {
+		if call.method == method {
+			id, _ := strconv.ParseInt(call.params.Get("chat_id"), 10, 64)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// okResponse успешный ответ с результатом result
+func okResponse(result interface{}) tgbotapi.APIResponse {
+	raw, _ := json.Marshal(result)
+	return tgbotapi.APIResponse{Ok: true, Result: raw}
+}
+
+// errorResponse ответ с ошибкой Bot API
+func errorResponse(code int, description string, params *tgbotapi.ResponseParameters) tgbotapi.APIResponse {
+	return tgbotapi.APIResponse{ErrorCode: code, Description: description, Parameters: params}
+}
+
+var chatNotFound = errorResponse(400, "Bad Request: chat not found", nil)
+
+func TestChatIDCandidates(t *testing.T) {
+	tests := []struct {
+		chatID int64
+		want   []int64
+	}{
+		{chatID: 12345, want: []int64{12345, -10012345, -12345}},
+		{chatID: 1, want: []int64{1, -1001, -1}},
+		{chatID: -12345, want: []int64{-12345}},
+		{chatID: -10012345, want: []int64{-10012345}},
+		{chatID: 0, want: []int64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.FormatInt(tt.chatID, 10), func(t *testing.T) {
+			if got := chatIDCandidates(tt.chatID); !slices.Equal(got, tt.want) {
+				t.Errorf("chatIDCandidates(%d) = %v, want %v", tt.chatID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrongChatID(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "chat not found", err: ParseAPIError(apiError(400, "Bad Request: chat not found", tgbotapi.ResponseParameters{})), want: true},
+		{name: "forbidden", err: ParseAPIError(apiError(403, "Forbidden: bot is not a member of the channel chat", tgbotapi.ResponseParameters{})), want: true},
+		{name: "blocked", err: ParseAPIError(apiError(403, "Forbidden: bot was blocked by the user", tgbotapi.ResponseParameters{})), want: false},
+		{name: "flood wait", err: ParseAPIError(apiError(429, "Too Many Requests", tgbotapi.ResponseParameters{RetryAfter: 1})), want: false},
+		{name: "network", err: errors.New("connection reset"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrongChatID(tt.err); got != tt.want {
+				t.Errorf("wrongChatID(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCanPost(t *testing.T) {
+	noMessages := &tgbotapi.ChatPermissions{CanSendMessages: false}
+
+	tests := []struct {
+		name    string
+		chat    tgbotapi.Chat
+		member  tgbotapi.ChatMember
+		wantErr error
+	}{
+		{name: "private", chat: tgbotapi.Chat{ID: 42, Type: "private"}},
+		{name: "group member", chat: tgbotapi.Chat{ID: -1, Type: "group"}, member: tgbotapi.ChatMember{Status: "member"}},
+		{name: "left group", chat: tgbotapi.Chat{ID: -1, Type: "group"}, member: tgbotapi.ChatMember{Status: "left"}, wantErr: ErrBotNotMember},
+		{name: "kicked", chat: tgbotapi.Chat{ID: -1001, Type: "supergroup"}, member: tgbotapi.ChatMember{Status: "kicked"}, wantErr: ErrBotNotMember},
+		{name: "restricted", chat: tgbotapi.Chat{ID: -1001, Type: "supergroup"}, member: tgbotapi.ChatMember{Status: "restricted", CanSendMessages: false}, wantErr: ErrCannotPost},
+		{name: "restricted can send", chat: tgbotapi.Chat{ID: -1001, Type: "supergroup"}, member: tgbotapi.ChatMember{Status: "restricted", CanSendMessages: true}},
+		{name: "read only group", chat: tgbotapi.Chat{ID: -1001, Type: "supergroup", Permissions: noMessages}, member: tgbotapi.ChatMember{Status: "member"}, wantErr: ErrCannotPost},
+		{name: "admin in read only group", chat: tgbotapi.Chat{ID: -1001, Type: "supergroup", Permissions: noMessages}, member: tgbotapi.ChatMember{Status: "administrator"}},
+		{name: "channel subscriber", chat: tgbotapi.Chat{ID: -1002, Type: "channel"}, member: tgbotapi.ChatMember{Status: "member"}, wantErr: ErrCannotPost},
+		{name: "channel admin without posting", chat: tgbotapi.Chat{ID: -1002, Type: "channel"}, member: tgbotapi.ChatMember{Status: "administrator"}, wantErr: ErrCannotPost},
+		{name: "channel admin", chat: tgbotapi.Chat{ID: -1002, Type: "channel"}, member: tgbotapi.ChatMember{Status: "administrator", CanPostMessages: true}},
+		{name: "channel creator", chat: tgbotapi.Chat{ID: -1002, Type: "channel"}, member: tgbotapi.ChatMember{Status: "creator"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			api := withFakeAPI(b, func(call apiCall) tgbotapi.APIResponse {
+				return okResponse(tt.member)
+			})
+
+			err := b.checkCanPost(context.Background(), &tt.chat)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("checkCanPost error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.chat.IsPrivate() && len(api.calls) != 0 {
+				t.Errorf("private chat checked with %d requests", len(api.calls))
+			}
+			if !tt.chat.IsPrivate() && (len(api.calls) != 1 || api.calls[0].params.Get("user_id") != "1") {
+				t.Errorf("unexpected requests: %+v", api.calls)
+			}
+		})
+	}
+}
+
+func TestResolveChats(t *testing.T) {
+	admin := okResponse(tgbotapi.ChatMember{Status: "administrator"})
+
+	tests := []struct {
+		name       string
+		configured int64
+		chats      map[int64]tgbotapi.APIResponse // Ответы getChat по ID чата
+		want       int64
+		wantErr    error
+	}{
+		{
+			name:       "supergroup without prefix",
+			configured: 12345,
+			chats:      map[int64]tgbotapi.APIResponse{-10012345: okResponse(tgbotapi.Chat{ID: -10012345, Type: "supergroup"})},
+			want:       -10012345,
+		},
+		{
+			name:       "group without sign",
+			configured: 12345,
+			chats:      map[int64]tgbotapi.APIResponse{-12345: okResponse(tgbotapi.Chat{ID: -12345, Type: "group"})},
+			want:       -12345,
+		},
+		{
+			name:       "migrated group",
+			configured: -12345,
+			chats: map[int64]tgbotapi.APIResponse{
+				-12345: errorResponse(400, "Bad Request: group chat was upgraded to a supergroup chat", &tgbotapi.ResponseParameters{MigrateToChatID: -10099}),
+				-10099: okResponse(tgbotapi.Chat{ID: -10099, Type: "supergroup"}),
+			},
+			want: -10099,
+		},
+		{
+			name:       "not found",
+			configured: 12345,
+			want:       12345,
+			wantErr:    ErrChatNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			withFakeAPI(b, func(call apiCall) tgbotapi.APIResponse {
+				if call.method == "getChatMember" {
+					return admin
+				}
+				id, _ := strconv.ParseInt(call.params.Get("chat_id"), 10, 64)
+				if resp, ok := tt.chats[id]; ok {
+					return resp
+				}
+				return chatNotFound
+			})
+
+			err := b.ResolveChats(context.Background(), tt.configured)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("ResolveChats error = %v, want %v", err, tt.wantErr)
+			}
+			if got := b.ResolveChatID(tt.configured); got != tt.want {
+				t.Errorf("ResolveChatID(%d) = %d, want %d", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendMessageUnknownChatID(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	api := withFakeAPI(b, func(call apiCall) tgbotapi.APIResponse {
+		if call.params.Get("chat_id") != "-10012345" {
+			return chatNotFound
+		}
+		return okResponse(tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -10012345}})
+	})
+
+	for i := 0; i < 2; i++ {
+		chatID, _, err := b.SendMessageUnkownChatIdD(tgbotapi.NewMessage(12345, "Новая заявка"))
+		if err != nil || chatID != -10012345 {
+			t.Fatalf("send %d: chat %d, error %v", i, chatID, err)
+		}
+	}
+
+	// Первое сообщение перебирает варианты ID, второе сразу уходит в найденный чат
+	want := []int64{12345, -10012345, -10012345}
+	if got := api.chatIDs("sendMessage"); !slices.Equal(got, want) {
+		t.Errorf("sendMessage chats = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CommandScopes области меню команд по ключу обработчика в MessageHandlers или шаблону
+// маршрута Command, например "/help". Команды без областей попадают в меню по умолчанию.
+// ID чатов в областях можно указывать как в конфигурации: они заменяются через ResolveChatID.
+type CommandScopes map[string][]tgbotapi.BotCommandScope
+
+// CommandSet меню команд для одной области и языка
+type CommandSet struct {
+	Scope        tgbotapi.BotCommandScope
+	LanguageCode string // Пустой - для всех языков без своего меню
+	Commands     []tgbotapi.BotCommand
+}
+
+// commandNameRegex допустимое имя команды в меню Telegram
+var commandNameRegex = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// command команда меню, собранная из обработчика
+type command struct {
+	name         string
+	description  string
+	descriptions map[string]string
+	scopes       []tgbotapi.BotCommandScope
+}
+
+// describe возвращает описание команды на языке lang
+func (c command) describe(lang string) string {
+	if d, ok := c.descriptions[lang]; ok && d != "" {
+		return d
+	}
+	return c.description
+}
+
+// CommandSets собирает меню команд из обработчиков с описанием в MessageHandlers
+// и маршрутов Command в MessageRoutes. В меню области попадают и команды более широких областей,
+// потому что Telegram показывает только меню самой узкой подходящей области.
+func (app *Bot) CommandSets() []CommandSet {
+	commands := app.commands()
+
+	// Области и языки в порядке первого появления
+	var scopes []tgbotapi.BotCommandScope
+	languages := []string{""}
+	for _, cmd := range commands {
+		for _, scope := range cmd.scopes {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		for lang := range cmd.descriptions {
+			if !slices.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	sort.Strings(languages[1:])
+
+	var sets []CommandSet
+	for _, scope := range scopes {
+		for _, lang := range languages {
+			set := CommandSet{Scope: scope, LanguageCode: lang}
+			translated := false
+			for _, cmd := range commands {
+				if !coversAny(cmd.scopes, scope) {
+					continue
+				}
+				if _, ok := cmd.descriptions[lang]; ok {
+					translated = true
+				}
+				set.Commands = append(set.Commands, tgbotapi.BotCommand{
+					Command:     cmd.name,
+					Description: cmd.describe(lang),
+				})
+			}
+			// Меню на отдельном языке нужно, только если в нем есть перевод
+			if len(set.Commands) > 0 && (lang == "" || translated) {
+				sets = append(sets, set)
+			}
+		}
+	}
+	return sets
+}
+
+// RegisterCommands устанавливает меню команд из CommandSets через setMyCommands
+func (app *Bot) RegisterCommands(ctx context.Context) error {
+	var errs []error
+	for _, set := range app.CommandSets() {
+		config := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(set.Scope, set.LanguageCode, set.Commands...)
+		if _, err := app.Request(ctx, config); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set commands for scope %s %d: %w", set.Scope.Type, set.Scope.ChatID, err))
+			continue
+		}
+		app.logger.Info().
+			Str("scope", set.Scope.Type).
+			Int64("chat_id", set.Scope.ChatID).
+			Str("language", set.LanguageCode).
+			Int("commands", len(set.Commands)).
+			Msg("bot commands registered")
+	}
+	return errors.Join(errs...)
+}
+
+// HelpText возвращает список команд, которые Telegram показывает в меню чата обновления,
+// на языке пользователя. Права администраторов в группах не проверяются.
+func (app *Bot) HelpText(u tgbotapi.Update, header string) string {
+	chat, user := UpdateChat(u), UpdateUser(u)
+	if chat == nil {
+		return header
+	}
+
+	var candidates []tgbotapi.BotCommandScope
+	if chat.IsPrivate() {
+		candidates = append(candidates,
+			tgbotapi.NewBotCommandScopeChat(chat.ID),
+			tgbotapi.NewBotCommandScopeAllPrivateChats(),
+		)
+	} else {
+		if user != nil {
+			candidates = append(candidates, tgbotapi.NewBotCommandScopeChatMember(chat.ID, user.ID))
+		}
+		candidates = append(candidates,
+			tgbotapi.NewBotCommandScopeChat(chat.ID),
+			tgbotapi.NewBotCommandScopeAllGroupChats(),
+		)
+	}
+	candidates = append(candidates, tgbotapi.NewBotCommandScopeDefault())
+
+	lang := ""
+	if user != nil {
+		lang, _, _ = strings.Cut(user.LanguageCode, "-")
+	}
+
+	sets := app.CommandSets()
+	for _, scope := range candidates {
+		set, ok := findCommandSet(sets, scope, lang)
+		if !ok {
+			continue
+		}
+		var builder strings.Builder
+		builder.WriteString(header)
+		for _, cmd := range set.Commands {
+			builder.WriteString(fmt.Sprintf("\n/%s - %s", cmd.Command, cmd.Description))
+		}
+		return builder.String()
+	}
+	return header
+}
+
+// HelpHandler обработчик команды /help: отправляет HelpText с заголовком header
+func HelpHandler(header string) Handler {
+	return Handler{
+		Handle: func(b *Bot, u tgbotapi.Update) error {
+			chat := UpdateChat(u)
+			if chat == nil {
+				return nil
+			}
+			_, err := b.Send(context.Background(), tgbotapi.NewMessage(chat.ID, b.HelpText(u, header)))
+			return err
+		},
+		Description:  "Список команд",
+		Descriptions: map[string]string{"en": "List of commands"},
+	}
+}
+
+// commands собирает команды из состояний: сначала глобальные, затем остальные, по имени состояния.
+// Команда, объявленная в нескольких состояниях, берется из первого.
+func (app *Bot) commands() []command {
+	names := make([]string, 0, len(app.states))
+	for name := range app.states {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		gi, gj := app.states[names[i]].Global, app.states[names[j]].Global
+		if gi != gj {
+			return gi
+		}
+		return names[i] < names[j]
+	})
+
+	var commands []command
+	seen := make(map[string]bool)
+	add := func(key string, handler Handler) {
+		name := strings.TrimPrefix(strings.ToLower(key), "/")
+		// Шаблоны Prefix и Regex и команды с недопустимыми для меню символами пропускаются
+		if !strings.HasPrefix(key, "/") || !commandNameRegex.MatchString(name) || handler.Description == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		commands = append(commands, command{
+			name:         name,
+			description:  handler.Description,
+			descriptions: handler.Descriptions,
+			scopes:       app.scopesFor(key),
+		})
+	}
+
+	for _, name := range names {
+		state := app.states[name]
+		keys := make([]string, 0, len(state.MessageHandlers))
+		for key := range state.MessageHandlers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			add(key, state.MessageHandlers[key])
+		}
+		for _, route := range state.MessageRoutes {
+			add(route.Pattern, route.Handler)
+		}
+	}
+	return commands
+}
+
+// scopesFor возвращает области меню команды с ID чатов, найденными через ResolveChatID
+func (app *Bot) scopesFor(key string) []tgbotapi.BotCommandScope {
+	scopes := app.commandScopes[key]
+	if len(scopes) == 0 {
+		return []tgbotapi.BotCommandScope{tgbotapi.NewBotCommandScopeDefault()}
+	}
+
+	resolved := make([]tgbotapi.BotCommandScope, len(scopes))
+	for i, scope := range scopes {
+		if scope.ChatID != 0 {
+			scope.ChatID = app.ResolveChatID(scope.ChatID)
+		}
+		resolved[i] = scope
+	}
+	return resolved
+}
+
+// findCommandSet ищет меню области на языке lang или меню для всех языков
+func findCommandSet(sets []CommandSet, scope tgbotapi.BotCommandScope, lang string) (CommandSet, bool) {
+	var fallback *CommandSet
+	for i := range sets {
+		if sets[i].Scope != scope {
+			continue
+		}
+		if sets[i].LanguageCode == lang {
+			return sets[i], true
+		}
+		if sets[i].LanguageCode == "" {
+			fallback = &sets[i]
+		}
+	}
+	if fallback == nil {
+		return CommandSet{}, false
+	}
+	return *fallback, true
+}
+
+// coversAny проверяет, что команда из областей scopes видна в области target
+func coversAny(scopes []tgbotapi.BotCommandScope, target tgbotapi.BotCommandScope) bool {
+	for _, scope := range scopes {
+		if scopeCovers(scope, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeCovers проверяет, что область broad включает область narrow.
+// Личные чаты имеют положительный ID, группы - отрицательный.
+func scopeCovers(broad, narrow tgbotapi.BotCommandScope) bool {
+	if broad == narrow {
+		return true
+	}
+	switch broad.Type {
+	case "default":
+		return true
+	case "all_private_chats":
+		return narrow.Type == "chat" && narrow.ChatID > 0
+	case "all_group_chats":
+		return narrow.Type == "all_chat_administrators" || narrow.ChatID < 0
+	case "all_chat_administrators":
+		return narrow.Type == "chat_administrators"
+	case "chat":
+		return (narrow.Type == "chat_administrators" || narrow.Type == "chat_member") && narrow.ChatID == broad.ChatID
+	}
+	return false
+}
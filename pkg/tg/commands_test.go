@@ -0,0 +1,225 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Настроенный ID группы операторов и ID, найденный ResolveChats
+const (
+	configuredGroupID = 100
+	resolvedGroupID   = -100100
+)
+
+// newCommandsBot бот с командами в глобальных состояниях и меню операторов в группе
+func newCommandsBot(t *testing.T) *Bot {
+	t.Helper()
+	b := newTestBot(t, Config{
+		States: map[string]State{
+			"start": {
+				Global: true,
+				MessageHandlers: map[string]Handler{
+					"/start":  {Description: "Начать", Descriptions: map[string]string{"en": "Start"}},
+					"/help":   HelpHandler("Команды:"),
+					"/hidden": {},                           // Без описания не попадает в меню
+					"Меню":    {Description: "Кнопка меню"}, // Не команда
+				},
+				MessageRoutes: []Route{Prefix("/go", Handler{Description: "Шаблон"})},
+			},
+			"operator": {
+				Global:          true,
+				MessageHandlers: map[string]Handler{"/stats": {Description: "Статистика"}},
+				MessageRoutes:   []Route{Command("/take", Handler{Description: "Взять заявку"})},
+			},
+			"form": {
+				MessageHandlers: map[string]Handler{
+					"/start":    {Description: "Повтор команды глобального состояния"},
+					"/Bad-Name": {Description: "Недопустимое имя"},
+				},
+			},
+		},
+		CommandScopes: CommandScopes{
+			"/take":  {tgbotapi.NewBotCommandScopeChat(configuredGroupID)},
+			"/stats": {tgbotapi.NewBotCommandScopeChatAdministrators(configuredGroupID)},
+		},
+	})
+	b.chatIDs.Store(int64(configuredGroupID), int64(resolvedGroupID))
+	return b
+}
+
+func TestScopeCovers(t *testing.T) {
+	var (
+		defaultScope   = tgbotapi.NewBotCommandScopeDefault()
+		privateChats   = tgbotapi.NewBotCommandScopeAllPrivateChats()
+		groupChats     = tgbotapi.NewBotCommandScopeAllGroupChats()
+		allAdmins      = tgbotapi.NewBotCommandScopeAllChatAdministrators()
+		privateChat    = tgbotapi.NewBotCommandScopeChat(42)
+		group          = tgbotapi.NewBotCommandScopeChat(-100)
+		groupAdmins    = tgbotapi.NewBotCommandScopeChatAdministrators(-100)
+		groupMember    = tgbotapi.NewBotCommandScopeChatMember(-100, 42)
+		otherGroup     = tgbotapi.NewBotCommandScopeChat(-200)
+		otherGroupAdms = tgbotapi.NewBotCommandScopeChatAdministrators(-200)
+	)
+
+	tests := []struct {
+		name          string
+		broad, narrow tgbotapi.BotCommandScope
+		want          bool
+	}{
+		{name: "same", broad: group, narrow: group, want: true},
+		{name: "default covers all", broad: defaultScope, narrow: groupMember, want: true},
+		{name: "private chats cover private chat", broad: privateChats, narrow: privateChat, want: true},
+		{name: "private chats skip group", broad: privateChats, narrow: group, want: false},
+		{name: "group chats cover group", broad: groupChats, narrow: group, want: true},
+		{name: "group chats cover all admins", broad: groupChats, narrow: allAdmins, want: true},
+		{name: "group chats cover group admins", broad: groupChats, narrow: groupAdmins, want: true},
+		{name: "group chats skip private chat", broad: groupChats, narrow: privateChat, want: false},
+		{name: "all admins cover group admins", broad: allAdmins, narrow: groupAdmins, want: true},
+		{name: "all admins skip group", broad: allAdmins, narrow: group, want: false},
+		{name: "chat covers its admins", broad: group, narrow: groupAdmins, want: true},
+		{name: "chat covers its member", broad: group, narrow: groupMember, want: true},
+		{name: "chat skips other chat admins", broad: group, narrow: otherGroupAdms, want: false},
+		{name: "chat skips other chat", broad: group, narrow: otherGroup, want: false},
+		{name: "narrow does not cover broad", broad: groupAdmins, narrow: group, want: false},
+		{name: "nothing covers default", broad: groupChats, narrow: defaultScope, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeCovers(tt.broad, tt.narrow); got != tt.want {
+				t.Errorf("scopeCovers(%+v, %+v) = %v, want %v", tt.broad, tt.narrow, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandSets(t *testing.T) {
+	var (
+		stats   = tgbotapi.BotCommand{Command: "stats", Description: "Статистика"}
+		take    = tgbotapi.BotCommand{Command: "take", Description: "Взять заявку"}
+		help    = tgbotapi.BotCommand{Command: "help", Description: "Список команд"}
+		helpEn  = tgbotapi.BotCommand{Command: "help", Description: "List of commands"}
+		start   = tgbotapi.BotCommand{Command: "start", Description: "Начать"}
+		startEn = tgbotapi.BotCommand{Command: "start", Description: "Start"}
+
+		admins       = tgbotapi.NewBotCommandScopeChatAdministrators(resolvedGroupID)
+		group        = tgbotapi.NewBotCommandScopeChat(resolvedGroupID)
+		defaultScope = tgbotapi.NewBotCommandScopeDefault()
+	)
+
+	// Меню узкой области включает команды более широких областей
+	want := []CommandSet{
+		{Scope: admins, Commands: []tgbotapi.BotCommand{stats, take, help, start}},
+		{Scope: admins, LanguageCode: "en", Commands: []tgbotapi.BotCommand{stats, take, helpEn, startEn}},
+		{Scope: group, Commands: []tgbotapi.BotCommand{take, help, start}},
+		{Scope: group, LanguageCode: "en", Commands: []tgbotapi.BotCommand{take, helpEn, startEn}},
+		{Scope: defaultScope, Commands: []tgbotapi.BotCommand{help, start}},
+		{Scope: defaultScope, LanguageCode: "en", Commands: []tgbotapi.BotCommand{helpEn, startEn}},
+	}
+
+	got := newCommandsBot(t).CommandSets()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandSets() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFindCommandSet(t *testing.T) {
+	sets := newCommandsBot(t).CommandSets()
+	group := tgbotapi.NewBotCommandScopeChat(resolvedGroupID)
+
+	tests := []struct {
+		name  string
+		scope tgbotapi.BotCommandScope
+		lang  string
+		want  string // Язык найденного меню
+		found bool
+	}{
+		{name: "translated", scope: group, lang: "en", want: "en", found: true},
+		{name: "fallback", scope: group, lang: "de", want: "", found: true},
+		{name: "all languages", scope: group, lang: "", want: "", found: true},
+		{name: "unknown scope", scope: tgbotapi.NewBotCommandScopeAllPrivateChats(), lang: "en", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, ok := findCommandSet(sets, tt.scope, tt.lang)
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if ok && (set.Scope != tt.scope || set.LanguageCode != tt.want) {
+				t.Errorf("found set %+v %q, want %q", set.Scope, set.LanguageCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelpText(t *testing.T) {
+	groupUpdate := func(lang string) tgbotapi.Update {
+		return tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 42, LanguageCode: lang},
+			Chat: &tgbotapi.Chat{ID: resolvedGroupID, Type: "supergroup"},
+			Text: "/help",
+		}}
+	}
+	privateUpdate := func(lang string) tgbotapi.Update {
+		u := textUpdate(1, 42, "/help")
+		u.Message.From.LanguageCode = lang
+		return u
+	}
+
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   string
+	}{
+		{name: "private", update: privateUpdate("ru"), want: "Команды:\n/help - Список команд\n/start - Начать"},
+		{name: "private regional language", update: privateUpdate("en-US"), want: "Команды:\n/help - List of commands\n/start - Start"},
+		{name: "operators group", update: groupUpdate(""), want: "Команды:\n/take - Взять заявку\n/help - Список команд\n/start - Начать"},
+		{name: "operators group in english", update: groupUpdate("en"), want: "Команды:\n/take - Взять заявку\n/help - List of commands\n/start - Start"},
+		{name: "without chat", update: tgbotapi.Update{}, want: "Команды:"},
+	}
+
+	b := newCommandsBot(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.HelpText(tt.update, "Команды:"); got != tt.want {
+				t.Errorf("HelpText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterCommands(t *testing.T) {
+	b := newCommandsBot(t)
+	api := withFakeAPI(b, func(call apiCall) tgbotapi.APIResponse {
+		// Меню группы на английском не устанавливается
+		if call.params.Get("language_code") == "en" && call.params.Get("scope") == `{"type":"chat","chat_id":-100100}` {
+			return errorResponse(400, "Bad Request: chat not found", nil)
+		}
+		return okResponse(true)
+	})
+
+	err := b.RegisterCommands(context.Background())
+	if err == nil {
+		t.Fatal("failed set is not reported")
+	}
+
+	// Ошибка одного меню не останавливает установку остальных
+	sets := b.CommandSets()
+	if len(api.calls) != len(sets) {
+		t.Fatalf("setMyCommands called %d times, want %d", len(api.calls), len(sets))
+	}
+	for i, call := range api.calls {
+		var commands []tgbotapi.BotCommand
+		if err := json.Unmarshal([]byte(call.params.Get("commands")), &commands); err != nil {
+			t.Fatalf("call %d: bad commands: %v", i, err)
+		}
+		if call.method != "setMyCommands" || !reflect.DeepEqual(commands, sets[i].Commands) || call.params.Get("language_code") != sets[i].LanguageCode {
+			t.Errorf("call %d = %s %v, want commands %+v", i, call.method, call.params, sets[i])
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package tg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiagramFormat формат диаграммы состояний
+type DiagramFormat string
+
+const (
+	DiagramDOT     DiagramFormat = "dot"     // Graphviz: dot -Tsvg states.dot -o states.svg
+	DiagramMermaid DiagramFormat = "mermaid" // Mermaid flowchart, например для блока ```mermaid в Markdown
+)
+
+// diagramState состояние, подготовленное для отрисовки
+type diagramState struct {
+	name        string
+	global      bool
+	triggers    []string // Входы, на которые реагирует состояние, по строке на каждый
+	transitions []string // Объявленные переходы в существующие состояния
+}
+
+// RenderDiagram рисует карту состояний в формате format
+func RenderDiagram(states map[string]State, format DiagramFormat) (string, error) {
+	switch format {
+	case DiagramDOT:
+		return RenderDOT(states), nil
+	case DiagramMermaid:
+		return RenderMermaid(states), nil
+	}
+	return "", NewValidationError(ErrUnknownDiagramFormat, format)
+}
+
+// RenderDOT рисует карту состояний для Graphviz. Глобальные состояния собраны в кластер global,
+// в подписи состояния перечислены его обработчики, стрелки - объявленные Transitions.
+func RenderDOT(states map[string]State) string {
+	nodes := diagramStates(states)
+
+	var builder strings.Builder
+	builder.WriteString("digraph states {\n")
+	builder.WriteString("\trankdir=LR;\n")
+	builder.WriteString("\tnode [shape=box];\n")
+
+	builder.WriteString("\tsubgraph cluster_global {\n")
+	builder.WriteString("\t\tlabel=\"global\";\n")
+	builder.WriteString("\t\tstyle=dashed;\n")
+	for _, node := range nodes {
+		if node.global {
+			builder.WriteString(fmt.Sprintf("\t\t%s [label=%s, style=bold];\n", dotQuote(node.name), dotLabel(node)))
+		}
+	}
+	builder.WriteString("\t}\n")
+
+	for _, node := range nodes {
+		if !node.global {
+			builder.WriteString(fmt.Sprintf("\t%s [label=%s];\n", dotQuote(node.name), dotLabel(node)))
+		}
+	}
+	for _, node := range nodes {
+		for _, to := range node.transitions {
+			builder.WriteString(fmt.Sprintf("\t%s -> %s;\n", dotQuote(node.name), dotQuote(to)))
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// RenderMermaid рисует карту состояний как Mermaid flowchart. Глобальные состояния собраны
+// в подграф global, в подписи состояния перечислены его обработчики, стрелки - объявленные Transitions.
+func RenderMermaid(states map[string]State) string {
+	nodes := diagramStates(states)
+
+	// Имена состояний могут содержать символы, недопустимые в ID Mermaid
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.name] = fmt.Sprintf("s%d", i)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("flowchart LR\n")
+
+	builder.WriteString("\tsubgraph globals [global]\n")
+	for _, node := range nodes {
+		if node.global {
+			builder.WriteString(fmt.Sprintf("\t\t%s[\"%s\"]:::global\n", ids[node.name], mermaidLabel(node)))
+		}
+	}
+	builder.WriteString("\tend\n")
+
+	for _, node := range nodes {
+		if !node.global {
+			builder.WriteString(fmt.Sprintf("\t%s[\"%s\"]\n", ids[node.name], mermaidLabel(node)))
+		}
+	}
+	for _, node := range nodes {
+		for _, to := range node.transitions {
+			builder.WriteString(fmt.Sprintf("\t%s --> %s\n", ids[node.name], ids[to]))
+		}
+	}
+	builder.WriteString("\tclassDef global stroke-width:3px\n")
+	return builder.String()
+}
+
+// diagramStates собирает состояния по алфавиту. Переходы в необъявленные состояния пропускаются,
+// такую карту все равно отклонит NewBot.
+func diagramStates(states map[string]State) []diagramState {
+	names := sortedStateNames(states)
+	nodes := make([]diagramState, 0, len(names))
+	for _, name := range names {
+		state := states[name]
+		node := diagramState{name: name, global: state.Global, triggers: stateTriggers(state)}
+		for _, to := range state.Transitions {
+			if _, ok := states[to]; ok {
+				node.transitions = append(node.transitions, to)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// stateTriggers перечисляет обработчики состояния: вход, типы обновлений, сообщения, callback и CatchAllFunc
+func stateTriggers(state State) []string {
+	var triggers []string
+	if state.AtEntranceFunc != nil {
+		triggers = append(triggers, "entrance")
+	}
+
+	kinds := []struct {
+		name    string
+		handler *Handler
+	}{
+		{"contact", state.ContactHandler},
+		{"media", state.MediaHandler},
+		{"location", state.LocationHandler},
+		{"web_app_data", state.WebAppDataHandler},
+		{"inline_query", state.InlineQueryHandler},
+		{"chat_member", state.ChatMemberHandler},
+		{"chat_join_request", state.ChatJoinRequestHandler},
+	}
+	for _, kind := range kinds {
+		if kind.handler != nil {
+			triggers = append(triggers, kind.name)
+		}
+	}
+
+	for _, key := range sortedKeys(state.MessageHandlers) {
+		triggers = append(triggers, "message "+key)
+	}
+	for _, route := range state.MessageRoutes {
+		triggers = append(triggers, "message "+route.Pattern)
+	}
+	for _, key := range sortedKeys(state.CallbackHandlers) {
+		triggers = append(triggers, "callback "+key)
+	}
+	for _, route := range state.CallbackRoutes {
+		triggers = append(triggers, "callback "+route.Pattern)
+	}
+
+	if state.CatchAllFunc != nil {
+		triggers = append(triggers, "catch-all")
+	}
+	return triggers
+}
+
+// dotLabel подпись состояния для Graphviz: имя по центру, обработчики по левому краю
+func dotLabel(node diagramState) string {
+	var builder strings.Builder
+	builder.WriteString(dotEscape(node.name))
+	builder.WriteString(`\n`)
+	for _, trigger := range node.triggers {
+		builder.WriteString(dotEscape(trigger))
+		builder.WriteString(`\l`)
+	}
+	return `"` + builder.String() + `"`
+}
+
+// dotQuote возвращает строку в кавычках для Graphviz
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+// dotEscape экранирует строку для Graphviz
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s)
+}
+
+// mermaidLabel подпись состояния для Mermaid: имя жирным, обработчики с новой строки
+func mermaidLabel(node diagramState) string {
+	lines := make([]string, 0, len(node.triggers)+1)
+	lines = append(lines, "<b>"+mermaidEscape(node.name)+"</b>")
+	for _, trigger := range node.triggers {
+		lines = append(lines, mermaidEscape(trigger))
+	}
+	return strings.Join(lines, "<br/>")
+}
+
+// mermaidEscape заменяет символы, которые Mermaid разбирает в подписи, на коды
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(
+		"#", "#35;",
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\n", " ",
+	).Replace(s)
+}
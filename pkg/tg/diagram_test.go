@@ -0,0 +1,123 @@
+package tg
+
+import (
+	"errors"
+	"testing"
+)
+
+// diagramTestStates карта состояний со всеми видами обработчиков, переходом
+// в необъявленное состояние и символами, которые нужно экранировать
+func diagramTestStates() map[string]State {
+	noop := Handler{}
+	return map[string]State{
+		"start": {
+			Global:          true,
+			MessageHandlers: map[string]Handler{"/start": noop},
+			CallbackRoutes:  []Route{Pattern("take:{id}", noop)},
+			Transitions:     []string{"menu"},
+		},
+		"menu": {
+			AtEntranceFunc:  &noop,
+			ContactHandler:  &noop,
+			MessageHandlers: map[string]Handler{"Заявка": noop},
+			CatchAllFunc:    &noop,
+			Transitions:     []string{`form "a<b>"`, "missing"},
+		},
+		`form "a<b>"`: {
+			MessageRoutes: []Route{Prefix("#", noop)},
+			Transitions:   []string{"menu"},
+		},
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	want := `digraph states {
+	rankdir=LR;
+	node [shape=box];
+	subgraph cluster_global {
+		label="global";
+		style=dashed;
+		"start" [label="start\nmessage /start\lcallback take:{id}\l", style=bold];
+	}
+	"form \"a<b>\"" [label="form \"a<b>\"\nmessage #*\l"];
+	"menu" [label="menu\nentrance\lcontact\lmessage Заявка\lcatch-all\l"];
+	"form \"a<b>\"" -> "menu";
+	"menu" -> "form \"a<b>\"";
+	"start" -> "menu";
+}
+`
+	if got := RenderDOT(diagramTestStates()); got != want {
+		t.Errorf("RenderDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	want := `flowchart LR
+	subgraph globals [global]
+		s2["<b>start</b><br/>message /start<br/>callback take:{id}"]:::global
+	end
+	s0["<b>form #quot;a#lt;b#gt;#quot;</b><br/>message #35;*"]
+	s1["<b>menu</b><br/>entrance<br/>contact<br/>message Заявка<br/>catch-all"]
+	s0 --> s1
+	s1 --> s0
+	s2 --> s1
+	classDef global stroke-width:3px
+`
+	if got := RenderMermaid(diagramTestStates()); got != want {
+		t.Errorf("RenderMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderDiagram(t *testing.T) {
+	states := diagramTestStates()
+
+	tests := []struct {
+		format  DiagramFormat
+		want    string
+		wantErr error
+	}{
+		{format: DiagramDOT, want: RenderDOT(states)},
+		{format: DiagramMermaid, want: RenderMermaid(states)},
+		{format: "svg", wantErr: ErrUnknownDiagramFormat},
+		{format: "", wantErr: ErrUnknownDiagramFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := RenderDiagram(states, tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RenderDiagram error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderDiagram() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		dot     string
+		mermaid string
+	}{
+		{name: "plain", s: "menu", dot: "menu", mermaid: "menu"},
+		{name: "quotes", s: `say "hi"`, dot: `say \"hi\"`, mermaid: "say #quot;hi#quot;"},
+		{name: "backslash", s: `a\b`, dot: `a\\b`, mermaid: `a\b`},
+		{name: "tags", s: "<b>", dot: "<b>", mermaid: "#lt;b#gt;"},
+		{name: "hash", s: "#1", dot: "#1", mermaid: "#35;1"},
+		{name: "newline", s: "a\nb", dot: "a b", mermaid: "a b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dotEscape(tt.s); got != tt.dot {
+				t.Errorf("dotEscape(%q) = %q, want %q", tt.s, got, tt.dot)
+			}
+			if got := mermaidEscape(tt.s); got != tt.mermaid {
+				t.Errorf("mermaidEscape(%q) = %q, want %q", tt.s, got, tt.mermaid)
+			}
+		})
+	}
+}
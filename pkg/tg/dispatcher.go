@@ -0,0 +1,147 @@
+package tg
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	DefaultWorkers   = 16  // Количество обработчиков обновлений по умолчанию
+	DefaultQueueSize = 100 // Длина очереди одного обработчика по умолчанию
+)
+
+// OverflowPolicy поведение при заполненной очереди обработчика
+type OverflowPolicy string
+
+const (
+	// OverflowBlock ждать места в очереди. Получение новых обновлений приостанавливается,
+	// в режиме webhook Telegram повторит доставку позже
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDrop отбросить обновление и записать предупреждение в лог
+	OverflowDrop OverflowPolicy = "drop"
+)
+
+// OrderBy определяет, какие обновления обрабатываются строго по очереди
+type OrderBy string
+
+const (
+	OrderByUser OrderBy = "user" // Обновления одного пользователя по очереди
+	OrderByChat OrderBy = "chat" // Обновления одного чата по очереди
+)
+
+// DispatcherConfig настройки обработки обновлений.
+// Обновления распределяются между Workers обработчиками по пользователю или чату:
+// обновления одного пользователя (чата) всегда попадают к одному обработчику
+// и выполняются в порядке получения, разные пользователи обрабатываются параллельно.
+type DispatcherConfig struct {
+	Workers   int            // Количество обработчиков. По умолчанию DefaultWorkers
+	QueueSize int            // Длина очереди каждого обработчика. По умолчанию DefaultQueueSize
+	Overflow  OverflowPolicy // Поведение при заполненной очереди. По умолчанию OverflowBlock
+	OrderBy   OrderBy        // Ключ упорядочивания. По умолчанию OrderByUser
+}
+
+// withDefaults заполняет незаданные настройки и проверяет остальные
+func (c DispatcherConfig) withDefaults() (DispatcherConfig, error) {
+	if c.Workers < 0 {
+		return c, NewValidationError(ErrInvalidWorkers, c.Workers)
+	}
+	if c.QueueSize < 0 {
+		return c, NewValidationError(ErrInvalidQueueSize, c.QueueSize)
+	}
+	if c.Workers == 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+
+	switch c.Overflow {
+	case "":
+		c.Overflow = OverflowBlock
+	case OverflowBlock, OverflowDrop:
+	default:
+		return c, NewValidationError(ErrUnknownOverflowPolicy, c.Overflow)
+	}
+
+	switch c.OrderBy {
+	case "":
+		c.OrderBy = OrderByUser
+	case OrderByUser, OrderByChat:
+	default:
+		return c, NewValidationError(ErrUnknownOrderBy, c.OrderBy)
+	}
+
+	return c, nil
+}
+
+// newQueues создает очереди обработчиков
+func newQueues(c DispatcherConfig) []chan tgbotapi.Update {
+	queues := make([]chan tgbotapi.Update, c.Workers)
+	for i := range queues {
+		queues[i] = make(chan tgbotapi.Update, c.QueueSize)
+	}
+	return queues
+}
+
+// startWorkers запускает обработчики очередей. Обработчик завершается,
+// когда его очередь закрыта и все обновления из нее обработаны.
+func (app *Bot) startWorkers() {
+	for _, queue := range app.queues {
+		app.inflight.Add(1)
+		go func(queue <-chan tgbotapi.Update) {
+			defer app.inflight.Done()
+			for update := range queue {
+				app.processUpdate(update)
+			}
+		}(queue)
+	}
+}
+
+// closeQueues закрывает очереди обработчиков. Вызывается из Run после выхода из цикла,
+// поэтому в закрытые очереди ничего не отправляется.
+func (app *Bot) closeQueues() {
+	for _, queue := range app.queues {
+		close(queue)
+	}
+}
+
+// dispatch помещает обновление в очередь обработчика по ключу упорядочивания
+func (app *Bot) dispatch(ctx context.Context, update tgbotapi.Update) {
+	queue := app.queues[app.orderKey(update)%uint64(len(app.queues))]
+
+	if app.dispatcher.Overflow == OverflowDrop {
+		select {
+		case queue <- update:
+		default:
+			app.forgetUpdate(update)
+			app.logger.Warn().Int("update_id", update.UpdateID).Msg("update queue is full, update dropped")
+		}
+		return
+	}
+
+	select {
+	case queue <- update:
+	case <-ctx.Done():
+		app.forgetUpdate(update)
+		app.logger.Warn().Int("update_id", update.UpdateID).Msg("bot stopped, update dropped")
+	case <-app.stop:
+		app.forgetUpdate(update)
+		app.logger.Warn().Int("update_id", update.UpdateID).Msg("bot stopped, update dropped")
+	}
+}
+
+// orderKey возвращает ключ, по которому обновления обрабатываются по очереди
+func (app *Bot) orderKey(update tgbotapi.Update) uint64 {
+	user, chat := UpdateUser(update), UpdateChat(update)
+	if app.dispatcher.OrderBy == OrderByChat && chat != nil {
+		return uint64(chat.ID)
+	}
+	if user != nil {
+		return uint64(user.ID)
+	}
+	if chat != nil {
+		return uint64(chat.ID)
+	}
+	return 0
+}
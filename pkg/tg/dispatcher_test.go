@@ -0,0 +1,210 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDispatcherConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DispatcherConfig
+		want    DispatcherConfig
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			want: DispatcherConfig{Workers: DefaultWorkers, QueueSize: DefaultQueueSize, Overflow: OverflowBlock, OrderBy: OrderByUser},
+		},
+		{
+			name:   "custom",
+			config: DispatcherConfig{Workers: 2, QueueSize: 5, Overflow: OverflowDrop, OrderBy: OrderByChat},
+			want:   DispatcherConfig{Workers: 2, QueueSize: 5, Overflow: OverflowDrop, OrderBy: OrderByChat},
+		},
+		{name: "negative workers", config: DispatcherConfig{Workers: -1}, wantErr: ErrInvalidWorkers},
+		{name: "negative queue", config: DispatcherConfig{QueueSize: -1}, wantErr: ErrInvalidQueueSize},
+		{name: "unknown overflow", config: DispatcherConfig{Overflow: "retry"}, wantErr: ErrUnknownOverflowPolicy},
+		{name: "unknown order", config: DispatcherConfig{OrderBy: "message"}, wantErr: ErrUnknownOrderBy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.withDefaults()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("withDefaults() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("withDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// groupUpdate сообщение пользователя userID в групповом чате chatID
+func groupUpdate(updateID int, userID, chatID int64, text string) tgbotapi.Update {
+	u := textUpdate(updateID, userID, text)
+	u.Message.Chat = &tgbotapi.Chat{ID: chatID, Type: "supergroup"}
+	return u
+}
+
+func TestOrderKey(t *testing.T) {
+	var chatID int64 = -100200
+	chatPost := tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID, Type: "channel"}}}
+
+	tests := []struct {
+		name    string
+		orderBy OrderBy
+		update  tgbotapi.Update
+		want    uint64
+	}{
+		{name: "user in private chat", orderBy: OrderByUser, update: textUpdate(1, 42, "hi"), want: 42},
+		{name: "user in group", orderBy: OrderByUser, update: groupUpdate(1, 42, chatID, "hi"), want: 42},
+		{name: "chat in group", orderBy: OrderByChat, update: groupUpdate(1, 42, chatID, "hi"), want: uint64(chatID)},
+		{name: "channel post by user order", orderBy: OrderByUser, update: chatPost, want: uint64(chatID)},
+		{name: "callback by chat order", orderBy: OrderByChat, update: callbackUpdate(1, 42, "take"), want: 42},
+		{name: "no user and chat", orderBy: OrderByUser, update: tgbotapi.Update{UpdateID: 1}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}, Dispatcher: DispatcherConfig{OrderBy: tt.orderBy}})
+			if got := b.orderKey(tt.update); got != tt.want {
+				t.Errorf("orderKey() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow OverflowPolicy
+		stop     bool
+		queued   int
+	}{
+		{name: "drop", overflow: OverflowDrop, queued: 1},
+		{name: "block until stop", overflow: OverflowBlock, stop: true, queued: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{
+				States:     map[string]State{},
+				Dispatcher: DispatcherConfig{Workers: 1, QueueSize: 1, Overflow: tt.overflow},
+			})
+			// Обработчики не запущены: второе обновление не помещается в очередь
+			b.dispatch(context.Background(), textUpdate(1, 42, "first"))
+			if tt.stop {
+				close(b.stop)
+			}
+			b.dispatch(context.Background(), textUpdate(2, 42, "second"))
+
+			if got := len(b.queues[0]); got != tt.queued {
+				t.Errorf("queued %d updates, want %d", got, tt.queued)
+			}
+			if u := <-b.queues[0]; u.UpdateID != 1 {
+				t.Errorf("queued update %d, want 1", u.UpdateID)
+			}
+		})
+	}
+}
+
+func TestDispatchOrder(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled = make(map[int64][]string)
+		done    = make(chan struct{}, 20)
+	)
+	b := newTestBot(t, Config{
+		Mode:       UpdateModeWebhook,
+		Webhook:    WebhookConfig{URL: "https://example.com/tg"},
+		Dispatcher: DispatcherConfig{Workers: 4},
+		States: map[string]State{
+			"start": {
+				Global: true,
+				CatchAllFunc: &Handler{Handle: func(_ *Bot, u tgbotapi.Update) error {
+					// Первые обновления обрабатываются дольше: без очереди порядок бы нарушился
+					if u.Message.Text == "1" {
+						time.Sleep(20 * time.Millisecond)
+					}
+					mu.Lock()
+					handled[u.Message.From.ID] = append(handled[u.Message.From.ID], u.Message.Text)
+					mu.Unlock()
+					done <- struct{}{}
+					return nil
+				}},
+			},
+		},
+	})
+	withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	run := runBot(b, context.Background())
+	updateID := 0
+	for _, text := range []string{"1", "2", "3"} {
+		for _, userID := range []int64{42, 43} {
+			updateID++
+			b.updates <- textUpdate(updateID, userID, text)
+		}
+	}
+	for i := 0; i < updateID; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("updates were not handled")
+		}
+	}
+	stopBot(t, b, run)
+
+	want := []string{"1", "2", "3"}
+	for _, userID := range []int64{42, 43} {
+		if got := handled[userID]; !reflect.DeepEqual(got, want) {
+			t.Errorf("user %d handled %v, want %v", userID, got, want)
+		}
+	}
+}
+
+func TestDispatchParallelUsers(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan int64, 2)
+	b := newTestBot(t, Config{
+		Mode:       UpdateModeWebhook,
+		Webhook:    WebhookConfig{URL: "https://example.com/tg"},
+		Dispatcher: DispatcherConfig{Workers: 2},
+		States: map[string]State{
+			"start": {
+				Global: true,
+				CatchAllFunc: &Handler{Handle: func(_ *Bot, u tgbotapi.Update) error {
+					if u.Message.From.ID == 42 {
+						<-release
+					}
+					handled <- u.Message.From.ID
+					return nil
+				}},
+			},
+		},
+	})
+	withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	run := runBot(b, context.Background())
+	// Пользователи 42 и 43 попадают к разным обработчикам
+	b.updates <- textUpdate(1, 42, "slow")
+	b.updates <- textUpdate(2, 43, "fast")
+
+	select {
+	case id := <-handled:
+		if id != 43 {
+			t.Errorf("handled user %d first, want 43", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("user 43 waits for user 42")
+	}
+	close(release)
+	<-handled
+	stopBot(t, b, run)
+}
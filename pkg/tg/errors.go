@@ -1,6 +1,14 @@
 package tg
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
 
 var (
 	// ErrStatesNil возникает когда карта состояний nil
@@ -14,6 +22,72 @@ var (
 
 	// ErrNegativeCleanup возникает при отрицательном интервале очистки
 	ErrNegativeCleanup = fmt.Errorf("cleanup interval cannot be negative")
+
+	// ErrUnknownUpdateMode возникает при неизвестном способе получения обновлений
+	ErrUnknownUpdateMode = fmt.Errorf("unknown update mode")
+
+	// ErrWebhookURLEmpty возникает, если в режиме webhook не указан URL
+	ErrWebhookURLEmpty = fmt.Errorf("webhook url is empty")
+
+	// ErrAlreadyRunning возникает при повторном запуске Run
+	ErrAlreadyRunning = fmt.Errorf("bot is already running")
+
+	// ErrInvalidWebhookSecret возникает при неверном формате secret_token webhook'а
+	ErrInvalidWebhookSecret = fmt.Errorf("webhook secret token must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+
+	// ErrStateNotFound возникает, если состояние пользователя не сохранено или истекло
+	ErrStateNotFound = fmt.Errorf("user state not found")
+
+	// ErrInvalidStateTable возникает при недопустимом имени таблицы состояний
+	ErrInvalidStateTable = fmt.Errorf("invalid state table name")
+
+	// ErrStateFileEmpty возникает, если для файлового хранилища не указан путь
+	ErrStateFileEmpty = fmt.Errorf("state file path is empty")
+
+	// ErrInvalidWorkers возникает при отрицательном количестве обработчиков обновлений
+	ErrInvalidWorkers = fmt.Errorf("workers count cannot be negative")
+
+	// ErrInvalidQueueSize возникает при отрицательной длине очереди обновлений
+	ErrInvalidQueueSize = fmt.Errorf("queue size cannot be negative")
+
+	// ErrUnknownOverflowPolicy возникает при неизвестном поведении при заполненной очереди
+	ErrUnknownOverflowPolicy = fmt.Errorf("unknown queue overflow policy")
+
+	// ErrUnknownOrderBy возникает при неизвестном ключе упорядочивания обновлений
+	ErrUnknownOrderBy = fmt.Errorf("unknown update order key")
+
+	// ErrHandlerPanic возникает, если обработчик запаниковал
+	ErrHandlerPanic = fmt.Errorf("handler panicked")
+
+	// ErrAccessDenied возникает, если у пользователя нет доступа к обработчику
+	ErrAccessDenied = fmt.Errorf("access denied")
+
+	// ErrNoWebAppData возникает, если в обновлении нет данных Mini App
+	ErrNoWebAppData = fmt.Errorf("update has no web app data")
+
+	// ErrBotNotMember возникает, если бот не состоит в чате или исключен из него
+	ErrBotNotMember = fmt.Errorf("bot is not a member of the chat")
+
+	// ErrCannotPost возникает, если у бота нет права отправлять сообщения в чат
+	ErrCannotPost = fmt.Errorf("bot is not allowed to post in the chat")
+
+	// ErrInvalidStateGraph возникает, если в карте состояний есть ошибки. Подробности в ValidationError.Value
+	ErrInvalidStateGraph = fmt.Errorf("invalid state graph")
+
+	// ErrUnknownTransition возникает при переходе в необъявленное состояние
+	ErrUnknownTransition = fmt.Errorf("transition to undefined state")
+
+	// ErrGlobalEntrance возникает, если у глобального состояния задан AtEntranceFunc
+	ErrGlobalEntrance = fmt.Errorf("global state has entrance function")
+
+	// ErrDuplicateGlobalHandler возникает, если один ключ обработчика объявлен в нескольких глобальных состояниях
+	ErrDuplicateGlobalHandler = fmt.Errorf("handler key is declared in several global states")
+
+	// ErrUnreachableState возникает, если в состояние нельзя попасть ни из одного глобального состояния
+	ErrUnreachableState = fmt.Errorf("state is unreachable")
+
+	// ErrUnknownDiagramFormat возникает при неизвестном формате диаграммы состояний
+	ErrUnknownDiagramFormat = fmt.Errorf("unknown diagram format")
 )
 
 // ValidationError представляет ошибку валидации с дополнительной информацией
@@ -26,6 +100,14 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("%v: %v", e.Err, e.Value)
 }
 
+// Unwrap позволяет проверять причину через errors.Is, в том числе причины из Value
+func (e *ValidationError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{e.Err, err}
+	}
+	return []error{e.Err}
+}
+
 // NewValidationError создает новую ошибку валидации
 func NewValidationError(err error, value interface{}) error {
 	return &ValidationError{
@@ -33,3 +115,84 @@ func NewValidationError(err error, value interface{}) error {
 		Value: value,
 	}
 }
+
+// Причины ошибок Bot API. Проверяются через errors.Is на ошибках, которые возвращает Bot.
+var (
+	// ErrFloodWait превышен лимит запросов, повторить можно через APIError.RetryAfter
+	ErrFloodWait = fmt.Errorf("telegram flood wait")
+
+	// ErrChatNotFound чат не существует или бот в нем не состоит
+	ErrChatNotFound = fmt.Errorf("telegram chat not found")
+
+	// ErrBotBlocked пользователь заблокировал бота или удалил аккаунт
+	ErrBotBlocked = fmt.Errorf("telegram bot blocked by user")
+
+	// ErrForbidden у бота нет доступа к чату: исключен из группы, пользователь не начинал диалог и т.п.
+	ErrForbidden = fmt.Errorf("telegram forbidden")
+
+	// ErrMessageNotModified новое содержимое сообщения совпадает с текущим
+	ErrMessageNotModified = fmt.Errorf("telegram message not modified")
+
+	// ErrChatMigrated группа преобразована в супергруппу с ID APIError.MigrateToChatID
+	ErrChatMigrated = fmt.Errorf("telegram chat migrated to supergroup")
+
+	// ErrServer внутренняя ошибка Telegram, запрос можно повторить
+	ErrServer = fmt.Errorf("telegram server error")
+)
+
+// APIError ошибка Bot API с разобранной причиной
+type APIError struct {
+	Code            int           // Код ошибки Bot API
+	Description     string        // Описание от Telegram
+	RetryAfter      time.Duration // Через сколько можно повторить запрос для ErrFloodWait
+	MigrateToChatID int64         // Новый ID чата для ErrChatMigrated
+	reason          error         // Причина из ErrFloodWait, ErrChatNotFound и т.д., nil - неизвестна
+	err             *tgbotapi.Error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+// Unwrap позволяет проверять причину и исходную ошибку tgbotapi через errors.Is и errors.As
+func (e *APIError) Unwrap() []error {
+	if e.reason == nil {
+		return []error{e.err}
+	}
+	return []error{e.reason, e.err}
+}
+
+// ParseAPIError преобразует ошибку tgbotapi в *APIError. Остальные ошибки возвращаются без изменений.
+func ParseAPIError(err error) error {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return err
+	}
+
+	apiErr := &APIError{
+		Code:            tgErr.Code,
+		Description:     tgErr.Message,
+		RetryAfter:      time.Duration(tgErr.RetryAfter) * time.Second,
+		MigrateToChatID: tgErr.MigrateToChatID,
+		err:             tgErr,
+	}
+
+	description := strings.ToLower(tgErr.Message)
+	switch {
+	case tgErr.Code == http.StatusTooManyRequests || tgErr.RetryAfter > 0:
+		apiErr.reason = ErrFloodWait
+	case tgErr.MigrateToChatID != 0:
+		apiErr.reason = ErrChatMigrated
+	case strings.Contains(description, "message is not modified"):
+		apiErr.reason = ErrMessageNotModified
+	case strings.Contains(description, "chat not found"):
+		apiErr.reason = ErrChatNotFound
+	case strings.Contains(description, "blocked by the user"), strings.Contains(description, "user is deactivated"):
+		apiErr.reason = ErrBotBlocked
+	case tgErr.Code == http.StatusForbidden:
+		apiErr.reason = ErrForbidden
+	case tgErr.Code >= http.StatusInternalServerError:
+		apiErr.reason = ErrServer
+	}
+	return apiErr
+}
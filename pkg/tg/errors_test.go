@@ -0,0 +1,85 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// apiError ошибка tgbotapi с кодом и описанием
+func apiError(code int, message string, params tgbotapi.ResponseParameters) error {
+	return &tgbotapi.Error{Code: code, Message: message, ResponseParameters: params}
+}
+
+func TestParseAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		reason     error
+		retryAfter time.Duration
+		migrateTo  int64
+	}{
+		{name: "flood wait", err: apiError(429, "Too Many Requests: retry after 5", tgbotapi.ResponseParameters{RetryAfter: 5}), reason: ErrFloodWait, retryAfter: 5 * time.Second},
+		{name: "retry after without 429", err: apiError(400, "Bad Request", tgbotapi.ResponseParameters{RetryAfter: 2}), reason: ErrFloodWait, retryAfter: 2 * time.Second},
+		{name: "migrated", err: apiError(400, "Bad Request: group chat was upgraded to a supergroup chat", tgbotapi.ResponseParameters{MigrateToChatID: -1001234}), reason: ErrChatMigrated, migrateTo: -1001234},
+		{name: "not modified", err: apiError(400, "Bad Request: message is not modified: specified new message content is the same", tgbotapi.ResponseParameters{}), reason: ErrMessageNotModified},
+		{name: "chat not found", err: apiError(400, "Bad Request: chat not found", tgbotapi.ResponseParameters{}), reason: ErrChatNotFound},
+		{name: "blocked", err: apiError(403, "Forbidden: bot was blocked by the user", tgbotapi.ResponseParameters{}), reason: ErrBotBlocked},
+		{name: "deactivated", err: apiError(403, "Forbidden: user is deactivated", tgbotapi.ResponseParameters{}), reason: ErrBotBlocked},
+		{name: "kicked", err: apiError(403, "Forbidden: bot was kicked from the group chat", tgbotapi.ResponseParameters{}), reason: ErrForbidden},
+		{name: "server", err: apiError(502, "Bad Gateway", tgbotapi.ResponseParameters{}), reason: ErrServer},
+		{name: "unknown", err: apiError(400, "Bad Request: message text is empty", tgbotapi.ResponseParameters{}), reason: nil},
+		{name: "wrapped", err: fmt.Errorf("send: %w", apiError(400, "Bad Request: chat not found", tgbotapi.ResponseParameters{})), reason: ErrChatNotFound},
+	}
+
+	reasons := []error{ErrFloodWait, ErrChatMigrated, ErrMessageNotModified, ErrChatNotFound, ErrBotBlocked, ErrForbidden, ErrServer}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseAPIError(tt.err)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("ParseAPIError returned %T", err)
+			}
+			for _, reason := range reasons {
+				if want := reason == tt.reason; errors.Is(err, reason) != want {
+					t.Errorf("errors.Is(%v) = %v, want %v", reason, !want, want)
+				}
+			}
+			if apiErr.RetryAfter != tt.retryAfter || apiErr.MigrateToChatID != tt.migrateTo {
+				t.Errorf("retry after %v, migrate to %d", apiErr.RetryAfter, apiErr.MigrateToChatID)
+			}
+
+			var tgErr *tgbotapi.Error
+			if !errors.As(err, &tgErr) {
+				t.Error("original tgbotapi error is not available")
+			}
+		})
+	}
+}
+
+func TestParseAPIErrorPassThrough(t *testing.T) {
+	if ParseAPIError(nil) != nil {
+		t.Error("nil error must stay nil")
+	}
+	plain := errors.New("connection reset")
+	if err := ParseAPIError(plain); err != plain {
+		t.Errorf("non API error changed to %v", err)
+	}
+}
+
+func TestValidationErrorUnwrap(t *testing.T) {
+	err := NewValidationError(ErrInvalidStateGraph, errors.Join(NewValidationError(ErrUnreachableState, "menu")))
+	for _, target := range []error{ErrInvalidStateGraph, ErrUnreachableState} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(%v) = false", target)
+		}
+	}
+	if errors.Is(NewValidationError(ErrStatesNil, "value"), ErrUnreachableState) {
+		t.Error("non error value must not be unwrapped")
+	}
+}
@@ -1,312 +1,86 @@
 package tg
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (app *Bot) SendMessageUnkownChatIdD(msg tgbotapi.MessageConfig) (int64, tgbotapi.Message, error) {
-	originalChatId := msg.ChatID
-	var sendedMsg tgbotapi.Message
-	var err error
-
-	app.logger.Info().
-		Int64("originalChatId", originalChatId).
-		Str("messageText", msg.Text[:min(len(msg.Text), 50)]).
-		Msg("Начинаем попытку отправки сообщения")
-
-	// Попытка 1: отправка с оригинальным ID
-	app.logger.Info().
-		Int64("chatId", msg.ChatID).
-		Msg("Попытка 1: Отправка с оригинальным ID")
-
-	sendedMsg, err = app.SendMessage(msg)
-	if err == nil {
-		app.logger.Info().
-			Int64("chatId", msg.ChatID).
-			Int("messageId", sendedMsg.MessageID).
-			Msg("Успешная отправка с оригинальным ID")
-		return msg.ChatID, sendedMsg, nil
-	}
-	app.logger.Error().
-		Int64("chatId", msg.ChatID).
-		Err(err).
-		Msg("Ошибка при отправке с оригинальным ID")
-
-	// Попытка 2: отправка с префиксом -100
-	newChatID := addNegative100Prefix(msg.ChatID)
-	app.logger.Info().
-		Int64("originalChatId", msg.ChatID).
-		Int64("newChatId", newChatID).
-		Msg("Попытка 2: Отправка с префиксом -100")
-
-	msg.ChatID = newChatID
-	sendedMsg, err = app.SendMessage(msg)
-	if err == nil {
-		app.logger.Info().
-			Int64("chatId", msg.ChatID).
-			Int("messageId", sendedMsg.MessageID).
-			Msg("Успешная отправка с префиксом -100")
-		return msg.ChatID, sendedMsg, nil
-	}
-	app.logger.Error().
-		Int64("chatId", msg.ChatID).
-		Err(err).
-		Msg("Ошибка при отправке с префиксом -100")
-
-	// Попытка 3: отправка с отрицательным ID
-	negativeChatID := originalChatId * -1
-	app.logger.Info().
-		Int64("originalChatId", originalChatId).
-		Int64("negativeChatId", negativeChatID).
-		Msg("Попытка 3: Отправка с отрицательным ID")
-
-	msg.ChatID = negativeChatID
-	sendedMsg, err = app.SendMessage(msg)
-	if err == nil {
-		app.logger.Info().
-			Int64("chatId", msg.ChatID).
-			Int("messageId", sendedMsg.MessageID).
-			Msg("Успешная отправка с отрицательным ID")
-		return msg.ChatID, sendedMsg, nil
-	}
-	app.logger.Error().
-		Int64("chatId", msg.ChatID).
-		Err(err).
-		Msg("Ошибка при отправке с отрицательным ID")
-
-	// Все попытки неудачны
-	app.logger.Error().
-		Int64("originalChatId", originalChatId).
-		Int64("lastTriedChatId", msg.ChatID).
-		Err(err).
-		Msg("Все попытки отправки сообщения неудачны")
-
-	return msg.ChatID, sendedMsg, fmt.Errorf("failed all attempts to send message: %w", err)
-}
-
-func addNegative100Prefix(num int64) int64 {
-	str := fmt.Sprintf("-100%d", num)
-	result, _ := strconv.ParseInt(str, 10, 64)
-	return result
-}
-
-// SendMessage синхронная функция для отправки сообщения
+// SendDeleteMessage удаляет сообщение и возвращает ответ API
 func (app *Bot) SendDeleteMessage(msg tgbotapi.DeleteMessageConfig) (*tgbotapi.APIResponse, error) {
-	sendedMsg, err := app.sendDeleteMessage(msg)
-	if err != nil {
-		return sendedMsg, err
-	}
-	return sendedMsg, nil
+	return app.Request(context.Background(), msg)
 }
 
-// sendMessage асинхронная функция, которая с помощью waitgroup дожидается результатов от отправки сообщения
-func (app *Bot) sendDeleteMessage(msg tgbotapi.DeleteMessageConfig) (*tgbotapi.APIResponse, error) {
-	app.CheckAPI()
-
-	// Устанавливаем глобальные параметры
-	sendedMsg, err := app.BotAPI.Request(msg)
-	if err != nil {
-		return nil, err
-	}
-
-	return sendedMsg, nil
-}
-
-// SendMessageRepet делает несколько попыток отправки сообщений.
-// Останавливает попытки после первой успешной.
+// SendMessageRepet делает до numberRepetion попыток отправки сообщения по правилам Config.Retry.
+// Постоянные ошибки, например ErrBotBlocked, возвращаются сразу.
 func (app *Bot) SendMessageRepet(msg tgbotapi.MessageConfig, numberRepetion int) (tgbotapi.Message, error) {
-	for i := 0; i < numberRepetion; i++ {
-		sendedMsg, err := app.SendMessage(msg)
-		if err != nil {
-			app.logger.Info().
-				Int("attempt", i).
-				Err(err).
-				Msg("Ошибка при отправке сообщения с повтором")
-		} else {
-			return sendedMsg, nil
-		}
-	}
-	return tgbotapi.Message{}, fmt.Errorf("ни одна попытка не оказалось результативной")
-}
-
-// SendMessage синхронная функция для отправки сообщения
-func (app *Bot) SendMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
-	sendedMsg, err := app.sendMessage(msg)
-	if err != nil {
-		return sendedMsg, err
-	}
-	return sendedMsg, nil
-}
-
-// sendMessage асинхронная функция, которая с помощью waitgroup дожидается результатов от отправки сообщения
-func (app *Bot) sendMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
-	app.CheckMessage(msg.ChatID)
-
-	sendedMsg, err := app.BotAPI.Send(msg)
+	sendedMsg, err := app.send(context.Background(), msg, numberRepetion)
 	if err != nil {
-		return sendedMsg, err
+		return tgbotapi.Message{}, fmt.Errorf("failed to send message: %w", err)
 	}
-
 	return sendedMsg, nil
 }
 
-// SendPinMessageEvent синхронная функция для отправки события на закрепление сообщения
-func (app *Bot) SendPinMessageEvent(messageID int, ChatID int64, disableNotification bool) (*tgbotapi.APIResponse, error) {
-	APIResponse, err := app.sendPinMessageEvent(messageID, ChatID, disableNotification)
-	if err != nil {
-		return APIResponse, err
-	}
-	return APIResponse, nil
+// SendMessage отправляет текстовое сообщение
+func (app *Bot) SendMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	return app.Send(context.Background(), msg)
 }
 
-// sendPinMessageEvent асинхронная функция, которая с помощью waitgroup дожидается результатов закрепления сообщения
+// SendPinMessageEvent закрепляет сообщение в чате.
 // DisableNotification - если true, уведомление о закреплении не будет отправлено
-func (app *Bot) sendPinMessageEvent(messageID int, ChatID int64, disableNotification bool) (*tgbotapi.APIResponse, error) {
-
-	// Закрепление отправленного сообщения
+func (app *Bot) SendPinMessageEvent(messageID int, ChatID int64, disableNotification bool) (*tgbotapi.APIResponse, error) {
 	pinConfig := tgbotapi.PinChatMessageConfig{
 		ChatID:              ChatID,
 		MessageID:           messageID,
 		DisableNotification: disableNotification,
 	}
-
-	app.CheckAPI()
-
-	// Устанавливаем глобальные параметры
-	APIResponse, err := app.BotAPI.Request(pinConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return APIResponse, nil
+	return app.Request(context.Background(), pinConfig)
 }
 
-// SendSticker синхронная функция для отправки стикера
+// SendSticker отправляет стикер
 func (app *Bot) SendSticker(stickerID string, chatID int64) (*tgbotapi.Message, error) {
-	sendedMsg, err := app.sendSticker(stickerID, chatID)
-	if err != nil {
-		return sendedMsg, err
-	}
-	return sendedMsg, nil
-}
-
-// sendSticker асинхронная функция, которая с помощью waitgroup дожидается результатов от отправки стикера
-func (app *Bot) sendSticker(stickerID string, chatID int64) (*tgbotapi.Message, error) {
-
-	msg := tgbotapi.NewSticker(chatID, tgbotapi.FileID(stickerID))
-
-	app.CheckAPI()
-	sendedMsg, err := app.BotAPI.Send(msg)
+	sendedMsg, err := app.Send(context.Background(), tgbotapi.NewSticker(chatID, tgbotapi.FileID(stickerID)))
 	if err != nil {
 		return nil, err
 	}
-
 	return &sendedMsg, nil
 }
 
-// SendUnPinAllMessageEvent синхронная функция для отправки события на открепление всех сообщений.
+// SendUnPinAllMessageEvent открепляет все сообщения в чате
 func (app *Bot) SendUnPinAllMessageEvent(ChannelUsername string, chatID int64) (*tgbotapi.APIResponse, error) {
-	sendedMsg, err := app.sendUnPinAllMessageEvent(ChannelUsername, chatID)
-	if err != nil {
-		return sendedMsg, err
-	}
-	return sendedMsg, nil
-}
-
-// sendUnPinAllMessageEvent асинхронная функция, которая с помощью waitgroup дожидается результатов от отправки события открепления всех сообщений
-func (app *Bot) sendUnPinAllMessageEvent(ChannelUsername string, chatID int64) (*tgbotapi.APIResponse, error) {
 	unpinConfig := tgbotapi.UnpinAllChatMessagesConfig{
 		ChatID:          chatID,
 		ChannelUsername: ChannelUsername,
 	}
-
-	app.CheckAPI()
-	APIresponse, err := app.BotAPI.Request(unpinConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return APIresponse, err
+	return app.Request(context.Background(), unpinConfig)
 }
 
+// EditMessageRepet делает до numberRepetion попыток редактирования сообщения по правилам Config.Retry
 func (app *Bot) EditMessageRepet(editMsg tgbotapi.EditMessageTextConfig, numberRepetion int) (*tgbotapi.APIResponse, error) {
-	var err error
-	var response *tgbotapi.APIResponse
-
-	for i := 0; i < numberRepetion; i++ {
-		response, err = app.editMessage(editMsg)
-		if err != nil {
-			app.logger.Info().
-				Int("attempt", i).
-				Err(err).
-				Msg("Ошибка при редактировании сообщения с повтором")
-		} else {
-			return response, nil
-		}
-	}
-	return nil, fmt.Errorf("ни одна попытка не стала результативной: %w", err)
-}
-
-// EditMessage синхронно редактирует сообщение
-func (app *Bot) EditMessage(editMsg tgbotapi.EditMessageTextConfig) (*tgbotapi.APIResponse, error) {
-	response, err := app.editMessage(editMsg)
+	response, err := app.request(context.Background(), editMsg, numberRepetion)
 	if err != nil {
-		return response, err
+		return nil, fmt.Errorf("failed to edit message: %w", err)
 	}
-
 	return response, nil
 }
 
-// editMessage редактирует сообщение в чате, отправив функцию редактирования в запросы
-func (app *Bot) editMessage(editMsg tgbotapi.EditMessageTextConfig) (*tgbotapi.APIResponse, error) {
-	app.CheckAPI()
-	// Устанавливаем глобальные параметры
-	response, err := app.BotAPI.Request(editMsg)
-	if err != nil {
-		return nil, err
-	}
-
-	return response, err
+// EditMessage редактирует текст сообщения
+func (app *Bot) EditMessage(editMsg tgbotapi.EditMessageTextConfig) (*tgbotapi.APIResponse, error) {
+	return app.Request(context.Background(), editMsg)
 }
 
+// DeleteMessageRepet делает до numberRepetion попыток удаления сообщения по правилам Config.Retry
 func (app *Bot) DeleteMessageRepet(msgToDelete tgbotapi.DeleteMessageConfig, numberRepetion int) error {
-	var err error
-
-	for i := 0; i < numberRepetion; i++ {
-		err = app.deleteMessage(msgToDelete)
-		if err != nil {
-			app.logger.Info().
-				Int("attempt", i).
-				Err(err).
-				Msg("Не удалось удалить сообщение из чата")
-		} else {
-			return nil
-		}
+	if _, err := app.request(context.Background(), msgToDelete, numberRepetion); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
 	}
-
-	return fmt.Errorf("ни одна попытка не стала результативной: %w", err)
+	return nil
 }
 
 // DeleteMessage удаляет сообщение
 func (app *Bot) DeleteMessage(msgToDelete tgbotapi.DeleteMessageConfig) error {
-	err := app.deleteMessage(msgToDelete)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (app *Bot) deleteMessage(deleteMsg tgbotapi.DeleteMessageConfig) error {
-	app.CheckAPI()
-	_, err := app.BotAPI.Request(deleteMsg)
-	if err != nil {
-		return err
-	}
-
+	_, err := app.Request(context.Background(), msgToDelete)
 	return err
 }
 
@@ -316,13 +90,13 @@ func (app *Bot) ShowAlert(CallbackQueryID string, alertText string) {
 	callback := tgbotapi.NewCallback(CallbackQueryID, alertText)
 	// Это заставит текст появиться во всплывающем окне
 	callback.ShowAlert = true
-	app.CheckAPI()
-	_, err := app.BotAPI.Request(callback)
-	if err != nil {
-		app.logger.Info().
-			Err(err).
-			Msg("Не удалось показать alert после CallbackQuery")
-	}
+	app.Request(context.Background(), callback)
+}
+
+// AnswerCallback отвечает на CallbackQuery всплывающим уведомлением,
+// которое исчезает само. Пустой текст только убирает индикатор загрузки на кнопке.
+func (app *Bot) AnswerCallback(CallbackQueryID string, text string) {
+	app.Request(context.Background(), tgbotapi.NewCallback(CallbackQueryID, text))
 }
 
 func CreateKeyboard(input []string, buttonsPerRow int) tgbotapi.ReplyKeyboardMarkup {
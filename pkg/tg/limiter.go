@@ -1,29 +1,180 @@
 package tg
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
-	GlobalLimit    = 30          // Максимум запросов в секунду к API
-	ChatLimit      = 1           // Максимум сообщений в секунду в один чат
-	MultiChatLimit = 30          // Максимум сообщений в секунду в разные чаты
-	WaitTime       = time.Second // Ждем полную секунду при превышении лимита
+	DefaultGlobalLimit  = rate.Limit(30)        // Запросов в секунду к API по умолчанию
+	DefaultGlobalBurst  = 30                    // Запас запросов к API по умолчанию
+	DefaultPrivateLimit = rate.Limit(1)         // Сообщений в секунду в личный чат по умолчанию
+	DefaultPrivateBurst = 1                     // Запас сообщений в личный чат по умолчанию
+	DefaultGroupLimit   = rate.Limit(20.0 / 60) // Сообщений в секунду в группу по умолчанию: 20 в минуту
+	DefaultGroupBurst   = 3                     // Запас сообщений в группу по умолчанию
+	chatBucketIdle      = 5 * time.Minute       // Время, после которого бюджет неактивного чата удаляется
+	chatBucketPrune     = time.Minute           // Интервал проверки неактивных чатов
 )
 
+// LimiterConfig бюджеты запросов к Bot API. Каждый бюджет - token bucket:
+// Limit токенов в секунду с запасом Burst. Незаданные значения берутся по умолчанию.
+type LimiterConfig struct {
+	GlobalLimit  rate.Limit // Все запросы бота
+	GlobalBurst  int
+	PrivateLimit rate.Limit // Сообщения в один личный чат
+	PrivateBurst int
+	GroupLimit   rate.Limit // Сообщения в одну группу или канал
+	GroupBurst   int
+}
+
+// withDefaults заполняет незаданные настройки
+func (c LimiterConfig) withDefaults() LimiterConfig {
+	if c.GlobalLimit <= 0 {
+		c.GlobalLimit = DefaultGlobalLimit
+	}
+	if c.GlobalBurst <= 0 {
+		c.GlobalBurst = DefaultGlobalBurst
+	}
+	if c.PrivateLimit <= 0 {
+		c.PrivateLimit = DefaultPrivateLimit
+	}
+	if c.PrivateBurst <= 0 {
+		c.PrivateBurst = DefaultPrivateBurst
+	}
+	if c.GroupLimit <= 0 {
+		c.GroupLimit = DefaultGroupLimit
+	}
+	if c.GroupBurst <= 0 {
+		c.GroupBurst = DefaultGroupBurst
+	}
+	return c
+}
+
+// chatBucket бюджет сообщений одного чата
+type chatBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter ограничивает частоту запросов к Bot API.
+// Общий бюджет и бюджеты чатов независимы: ожидание слота в одном чате
+// не задерживает отправку в другие. Блокировка держится только на время
+// резервирования токенов, само ожидание идет без блокировок.
 type Limiter struct {
-	mu sync.Mutex
+	config  LimiterConfig
+	global  *rate.Limiter
+	waiting atomic.Int64 // Запросы, ожидающие своей очереди
 
-	ChatTimes    map[int64]time.Time
-	MessageTimes []time.Time
-	ApiTimes     []time.Time
+	mu        sync.Mutex
+	chats     map[int64]*chatBucket
+	lastPrune time.Time
 }
 
-func NewLimiter() *Limiter {
+// NewLimiter создает лимитер с бюджетами из config
+func NewLimiter(config LimiterConfig) *Limiter {
+	config = config.withDefaults()
 	return &Limiter{
-		ChatTimes:    make(map[int64]time.Time),
-		MessageTimes: make([]time.Time, 0, MultiChatLimit),
-		ApiTimes:     make([]time.Time, 0, GlobalLimit),
+		config:    config,
+		global:    rate.NewLimiter(config.GlobalLimit, config.GlobalBurst),
+		chats:     make(map[int64]*chatBucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// Wait ждет слот для сообщения в чат chatID: в бюджете чата и в общем бюджете.
+// Личные чаты имеют положительный ID, группы и каналы - отрицательный.
+// Возвращает ошибку ctx, если он отменен раньше, чем появился слот.
+func (l *Limiter) Wait(ctx context.Context, chatID int64) error {
+	return l.wait(ctx, l.chat(chatID), l.global)
+}
+
+// WaitAPI ждет слот в общем бюджете для запроса, не отправляющего сообщений
+func (l *Limiter) WaitAPI(ctx context.Context) error {
+	return l.wait(ctx, l.global)
+}
+
+// QueueDepth возвращает количество запросов, ожидающих слота
+func (l *Limiter) QueueDepth() int {
+	return int(l.waiting.Load())
+}
+
+// chat возвращает бюджет чата, создавая его при первом обращении
+func (l *Limiter) chat(chatID int64) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > chatBucketPrune {
+		for id, bucket := range l.chats {
+			if now.Sub(bucket.lastSeen) > chatBucketIdle {
+				delete(l.chats, id)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	bucket, ok := l.chats[chatID]
+	if !ok {
+		limit, burst := l.config.PrivateLimit, l.config.PrivateBurst
+		if chatID < 0 {
+			limit, burst = l.config.GroupLimit, l.config.GroupBurst
+		}
+		bucket = &chatBucket{limiter: rate.NewLimiter(limit, burst)}
+		l.chats[chatID] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket.limiter
+}
+
+// wait резервирует по токену во всех бюджетах и ждет самого позднего.
+// При отмене ctx резервирования возвращаются в бюджеты.
+func (l *Limiter) wait(ctx context.Context, limiters ...*rate.Limiter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, len(limiters))
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+
+	var delay time.Duration
+	for _, limiter := range limiters {
+		r := limiter.ReserveN(now, 1)
+		if !r.OK() {
+			cancel()
+			return fmt.Errorf("rate limiter burst %d is too small", limiter.Burst())
+		}
+		reservations = append(reservations, r)
+		delay = max(delay, r.DelayFrom(now))
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		cancel()
+		return context.DeadlineExceeded
+	}
+
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
 	}
 }
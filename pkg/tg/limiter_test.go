@@ -0,0 +1,146 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// slowLimit пополнение бюджета, которого не дождаться за время теста
+const slowLimit = rate.Limit(1.0 / 3600)
+
+// tryWait ждет слот не дольше короткого дедлайна: лимитер сразу возвращает
+// DeadlineExceeded, если слот появится позже
+func tryWait(wait func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	return wait(ctx)
+}
+
+func TestLimiterConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LimiterConfig
+		want   LimiterConfig
+	}{
+		{
+			name:   "empty",
+			config: LimiterConfig{},
+			want:   LimiterConfig{DefaultGlobalLimit, DefaultGlobalBurst, DefaultPrivateLimit, DefaultPrivateBurst, DefaultGroupLimit, DefaultGroupBurst},
+		},
+		{
+			name:   "partial",
+			config: LimiterConfig{GlobalLimit: 10, GroupBurst: 5},
+			want:   LimiterConfig{10, DefaultGlobalBurst, DefaultPrivateLimit, DefaultPrivateBurst, DefaultGroupLimit, 5},
+		},
+		{
+			name:   "negative",
+			config: LimiterConfig{PrivateLimit: -1, PrivateBurst: -1},
+			want:   LimiterConfig{DefaultGlobalLimit, DefaultGlobalBurst, DefaultPrivateLimit, DefaultPrivateBurst, DefaultGroupLimit, DefaultGroupBurst},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.withDefaults(); got != tt.want {
+				t.Errorf("withDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterBudgets(t *testing.T) {
+	config := LimiterConfig{
+		GlobalLimit: slowLimit, GlobalBurst: 10,
+		PrivateLimit: slowLimit, PrivateBurst: 1,
+		GroupLimit: slowLimit, GroupBurst: 3,
+	}
+
+	tests := []struct {
+		name    string
+		chats   []int64 // Чаты, в которые отправляются сообщения по порядку
+		allowed int     // Сколько сообщений проходит без ожидания
+	}{
+		{name: "private chat", chats: []int64{1, 1}, allowed: 1},
+		{name: "group chat", chats: []int64{-100, -100, -100, -100}, allowed: 3},
+		{name: "independent chats", chats: []int64{1, 2, 3, -100, -200}, allowed: 5},
+		{name: "global budget", chats: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, allowed: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(config)
+			for i, chatID := range tt.chats {
+				err := tryWait(func(ctx context.Context) error { return l.Wait(ctx, chatID) })
+				if i < tt.allowed && err != nil {
+					t.Fatalf("message %d to chat %d: %v", i, chatID, err)
+				}
+				if i >= tt.allowed && !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("message %d to chat %d: error = %v, want deadline exceeded", i, chatID, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterWaitAPI(t *testing.T) {
+	l := NewLimiter(LimiterConfig{GlobalLimit: slowLimit, GlobalBurst: 2})
+	wait := func(ctx context.Context) error { return l.WaitAPI(ctx) }
+
+	for i := 0; i < 2; i++ {
+		if err := tryWait(wait); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := tryWait(wait); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("request over budget: error = %v, want deadline exceeded", err)
+	}
+	// Запрос к API расходует общий бюджет и для сообщений
+	if err := tryWait(func(ctx context.Context) error { return l.Wait(ctx, 1) }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("message over global budget: error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestLimiterQueueDepth(t *testing.T) {
+	l := NewLimiter(LimiterConfig{PrivateLimit: slowLimit, PrivateBurst: 1})
+	if err := l.Wait(context.Background(), 1); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.Wait(ctx, 1) }()
+
+	deadline := time.Now().Add(time.Second)
+	for l.QueueDepth() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth = %d, want 1", l.QueueDepth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled wait: error = %v, want context.Canceled", err)
+	}
+	if depth := l.QueueDepth(); depth != 0 {
+		t.Errorf("queue depth after cancel = %d, want 0", depth)
+	}
+}
+
+func TestLimiterCanceledContext(t *testing.T) {
+	l := NewLimiter(LimiterConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.Wait(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	// Отмененный запрос не расходует бюджет чата
+	if err := tryWait(func(ctx context.Context) error { return l.Wait(ctx, 1) }); err != nil {
+		t.Fatalf("message after canceled wait: %v", err)
+	}
+}
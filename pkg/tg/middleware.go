@@ -0,0 +1,98 @@
+package tg
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Middleware оборачивает обработчик общей логикой: логированием, проверкой прав и т.п.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain оборачивает обработчик в middleware. Первый middleware выполняется первым.
+func Chain(handler HandlerFunc, middlewares ...Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// DefaultMiddlewares middleware, которые используются, если Config.Middlewares равен nil
+func DefaultMiddlewares() []Middleware {
+	return []Middleware{Recover(), Logging()}
+}
+
+// wrap оборачивает обработчик состояния в глобальные middleware и middleware состояния
+func (app *Bot) wrap(state *State, handler HandlerFunc) HandlerFunc {
+	handler = Chain(handler, state.Middlewares...)
+	return Chain(handler, app.middlewares...)
+}
+
+// Recover перехватывает панику в обработчике и возвращает ее как ошибку
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(b *Bot, u tgbotapi.Update) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					b.logger.Error().
+						Int("update_id", u.UpdateID).
+						Str("stack", string(debug.Stack())).
+						Msgf("panic in handler: %v", r)
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+			return next(b, u)
+		}
+	}
+}
+
+// Logging записывает в лог каждый вызов обработчика с временем выполнения и ошибкой
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(b *Bot, u tgbotapi.Update) error {
+			start := time.Now()
+			err := next(b, u)
+
+			// Ошибку дополнительно записывает вызывающий код, здесь она только для полноты записи
+			event := b.logger.Info().Err(err)
+			if user := UpdateUser(u); user != nil {
+				event = event.Int64("user_id", user.ID).Str("username", user.UserName)
+			}
+			if chat := UpdateChat(u); chat != nil {
+				event = event.Int64("chat_id", chat.ID)
+			}
+			switch {
+			case u.Message != nil:
+				event = event.Str("command", u.Message.Text)
+			case u.CallbackQuery != nil:
+				event = event.Str("callback", u.CallbackQuery.Data)
+			}
+			event.Dur("duration", time.Since(start)).Msg("update handled")
+			return err
+		}
+	}
+}
+
+// AccessFunc проверяет, разрешено ли обновление
+type AccessFunc func(b *Bot, u tgbotapi.Update) bool
+
+// AdminOnly пропускает к обработчику только обновления, разрешенные allowed.
+// На запрещенные нажатия кнопок пользователь получает уведомление, сообщения игнорируются.
+func AdminOnly(allowed AccessFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(b *Bot, u tgbotapi.Update) error {
+			if allowed(b, u) {
+				return next(b, u)
+			}
+			if u.CallbackQuery != nil {
+				b.ShowAlert(u.CallbackQuery.ID, "Недостаточно прав")
+			}
+			if user := UpdateUser(u); user != nil {
+				return fmt.Errorf("%w: user %d", ErrAccessDenied, user.ID)
+			}
+			return ErrAccessDenied
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package tg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// traceMiddleware записывает в trace имя до и после вызова следующего обработчика
+func traceMiddleware(trace *[]string, name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(b *Bot, u tgbotapi.Update) error {
+			*trace = append(*trace, name+" before")
+			err := next(b, u)
+			*trace = append(*trace, name+" after")
+			return err
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var trace []string
+	handler := func(*Bot, tgbotapi.Update) error {
+		trace = append(trace, "handler")
+		return nil
+	}
+
+	chained := Chain(handler, traceMiddleware(&trace, "a"), traceMiddleware(&trace, "b"))
+	if err := chained(nil, tgbotapi.Update{}); err != nil {
+		t.Fatalf("chained handler failed: %v", err)
+	}
+	want := []string{"a before", "b before", "handler", "b after", "a after"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestWrapOrder(t *testing.T) {
+	var trace []string
+	states := map[string]State{
+		"start": {
+			Global:      true,
+			Middlewares: []Middleware{traceMiddleware(&trace, "state")},
+			MessageHandlers: map[string]Handler{"/start": {Handle: func(*Bot, tgbotapi.Update) error {
+				trace = append(trace, "handler")
+				return nil
+			}}},
+		},
+	}
+	b := newTestBot(t, Config{States: states, Middlewares: []Middleware{traceMiddleware(&trace, "global")}})
+
+	b.processUpdate(textUpdate(1, 42, "/start"))
+	// Глобальные middleware снаружи middleware состояния
+	want := []string{"global before", "state before", "handler", "state after", "global after"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		wantErr error
+	}{
+		{name: "ok", handler: func(*Bot, tgbotapi.Update) error { return nil }},
+		{name: "error", handler: func(*Bot, tgbotapi.Update) error { return errHandler }, wantErr: errHandler},
+		{name: "panic", handler: func(*Bot, tgbotapi.Update) error { panic("nil map") }, wantErr: ErrHandlerPanic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			err := Recover()(tt.handler)(b, textUpdate(1, 42, "/start"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	b := newTestBot(t, Config{States: map[string]State{}})
+	logger := zerolog.New(&buf)
+	b.logger = &logger
+
+	errHandler := errors.New("handler failed")
+	err := Logging()(func(*Bot, tgbotapi.Update) error { return errHandler })(b, callbackUpdate(1, 42, "form_take:7"))
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("error = %v, want %v", err, errHandler)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log entry %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"message":  "update handled",
+		"user_id":  float64(42),
+		"chat_id":  float64(42),
+		"callback": "form_take:7",
+		"error":    "handler failed",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("duration is not logged")
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   bool
+		update    tgbotapi.Update
+		wantErr   error
+		wantAlert bool
+	}{
+		{name: "allowed message", allowed: true, update: textUpdate(1, 42, "/stats")},
+		{name: "allowed callback", allowed: true, update: callbackUpdate(1, 42, "form_take:7")},
+		{name: "denied message", update: textUpdate(1, 42, "/stats"), wantErr: ErrAccessDenied},
+		{name: "denied callback", update: callbackUpdate(1, 42, "form_take:7"), wantErr: ErrAccessDenied, wantAlert: true},
+		{name: "denied without user", update: tgbotapi.Update{UpdateID: 1}, wantErr: ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+			called := false
+			handler := AdminOnly(func(*Bot, tgbotapi.Update) bool { return tt.allowed })(func(*Bot, tgbotapi.Update) error {
+				called = true
+				return nil
+			})
+			err := handler(b, tt.update)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if called != tt.allowed {
+				t.Errorf("handler called = %v, want %v", called, tt.allowed)
+			}
+
+			alerted := len(api.calls) == 1 && api.calls[0].method == "answerCallbackQuery" && api.calls[0].params.Get("show_alert") == "true"
+			if alerted != tt.wantAlert {
+				t.Errorf("alert = %v, want %v; calls %+v", alerted, tt.wantAlert, api.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts  = 3                      // Количество попыток запроса по умолчанию
+	DefaultRetryBaseDelay = 500 * time.Millisecond // Начальная пауза между повторами по умолчанию
+	DefaultRetryMaxDelay  = 30 * time.Second       // Максимальная пауза между повторами по умолчанию
+)
+
+// RetryPolicy правила повтора запросов к Bot API.
+// При ErrFloodWait запрос повторяется ровно через RetryAfter из ответа Telegram,
+// при сетевых ошибках и ErrServer - с экспоненциальной паузой от BaseDelay до MaxDelay.
+// Остальные ошибки постоянные и не повторяются.
+type RetryPolicy struct {
+	MaxAttempts int           // Количество попыток в Send и Request. По умолчанию DefaultRetryAttempts
+	BaseDelay   time.Duration // Пауза перед первым повтором. По умолчанию DefaultRetryBaseDelay
+	MaxDelay    time.Duration // Максимальная пауза. По умолчанию DefaultRetryMaxDelay
+}
+
+// withDefaults заполняет незаданные настройки
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryMaxDelay
+	}
+	return p
+}
+
+// Delay возвращает паузу перед повтором номер attempt (с 0) после ошибки err
+// и false, если ошибку повторять нельзя
+func (p RetryPolicy) Delay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Ошибка до ответа Telegram: сеть, таймаут
+		return p.backoff(attempt), !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch {
+	case errors.Is(err, ErrFloodWait):
+		return apiErr.RetryAfter, true
+	case errors.Is(err, ErrServer):
+		return p.backoff(attempt), true
+	default:
+		return 0, false
+	}
+}
+
+// backoff экспоненциальная пауза со случайным разбросом до четверти паузы
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 && p.BaseDelay<<attempt < p.MaxDelay {
+		delay = p.BaseDelay << attempt
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/4+1))
+}
+
+// retry выполняет op до успеха, постоянной ошибки или исчерпания attempts попыток.
+// Ошибки op разбираются через ParseAPIError, возвращается последняя ошибка.
+func (app *Bot) retry(ctx context.Context, attempts int, op func() error) error {
+	var err error
+	for attempt := 0; attempt < max(attempts, 1); attempt++ {
+		err = ParseAPIError(op())
+		if err == nil {
+			return nil
+		}
+
+		delay, ok := app.retryPolicy.Delay(err, attempt)
+		if !ok || attempt == attempts-1 {
+			return err
+		}
+
+		app.logger.Warn().
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Err(err).
+			Msg("telegram request failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		}
+	}
+	return err
+}
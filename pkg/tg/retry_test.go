@@ -0,0 +1,105 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+
+	tests := []struct {
+		name     string
+		err      error
+		attempt  int
+		retry    bool
+		min, max time.Duration
+	}{
+		{name: "flood wait", err: ParseAPIError(apiError(429, "Too Many Requests", tgbotapi.ResponseParameters{RetryAfter: 7})), retry: true, min: 7 * time.Second, max: 7 * time.Second},
+		{name: "server first", err: ParseAPIError(apiError(500, "Internal Server Error", tgbotapi.ResponseParameters{})), retry: true, min: 75 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "server third", err: ParseAPIError(apiError(500, "Internal Server Error", tgbotapi.ResponseParameters{})), attempt: 2, retry: true, min: 300 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "server capped", err: ParseAPIError(apiError(500, "Internal Server Error", tgbotapi.ResponseParameters{})), attempt: 40, retry: true, min: 750 * time.Millisecond, max: time.Second},
+		{name: "network", err: errors.New("connection reset"), retry: true, min: 75 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "canceled", err: context.Canceled, retry: false},
+		{name: "deadline", err: context.DeadlineExceeded, retry: false},
+		{name: "chat not found", err: ParseAPIError(apiError(400, "Bad Request: chat not found", tgbotapi.ResponseParameters{})), retry: false},
+		{name: "blocked", err: ParseAPIError(apiError(403, "Forbidden: bot was blocked by the user", tgbotapi.ResponseParameters{})), retry: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.Delay(tt.err, tt.attempt)
+			if retry != tt.retry {
+				t.Fatalf("retry = %v, want %v", retry, tt.retry)
+			}
+			if retry && (delay < tt.min || delay > tt.max) {
+				t.Errorf("delay = %v, want %v..%v", delay, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	policy := RetryPolicy{}.withDefaults()
+	if policy.MaxAttempts != DefaultRetryAttempts || policy.BaseDelay != DefaultRetryBaseDelay || policy.MaxDelay != DefaultRetryMaxDelay {
+		t.Errorf("defaults = %+v", policy)
+	}
+}
+
+func TestBotRetry(t *testing.T) {
+	serverErr := apiError(502, "Bad Gateway", tgbotapi.ResponseParameters{})
+	permanent := apiError(400, "Bad Request: chat not found", tgbotapi.ResponseParameters{})
+
+	tests := []struct {
+		name     string
+		errs     []error // Ошибки попыток по порядку, после них - успех
+		attempts int
+		calls    int
+		wantErr  error
+	}{
+		{name: "success", errs: nil, attempts: 3, calls: 1},
+		{name: "recovered", errs: []error{serverErr, serverErr}, attempts: 3, calls: 3},
+		{name: "exhausted", errs: []error{serverErr, serverErr, serverErr}, attempts: 3, calls: 3, wantErr: ErrServer},
+		{name: "permanent", errs: []error{permanent}, attempts: 3, calls: 1, wantErr: ErrChatNotFound},
+		{name: "single attempt", errs: []error{serverErr}, attempts: 1, calls: 1, wantErr: ErrServer},
+		{name: "zero attempts", errs: nil, attempts: 0, calls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}, Retry: RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}})
+
+			calls := 0
+			err := b.retry(context.Background(), tt.attempts, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("retry error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestBotRetryCanceled(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}, Retry: RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := b.retry(ctx, 3, func() error {
+		return apiError(502, "Bad Gateway", tgbotapi.ResponseParameters{})
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrServer) {
+		t.Errorf("retry error = %v, want server error joined with context.Canceled", err)
+	}
+}
@@ -0,0 +1,201 @@
+package tg
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Params параметры, извлеченные маршрутом из текста сообщения или данных callback
+type Params map[string]string
+
+// Get возвращает параметр или пустую строку
+func (p Params) Get(name string) string {
+	return p[name]
+}
+
+// Int64 возвращает параметр как число
+func (p Params) Int64(name string) (int64, error) {
+	return strconv.ParseInt(p[name], 10, 64)
+}
+
+// Args возвращает аргументы команды, разделенные пробелами
+func (p Params) Args() []string {
+	return strings.Fields(p[ParamArgs])
+}
+
+const (
+	// ParamArgs параметр с аргументами команды: для "/find ivan petrov" - "ivan petrov"
+	ParamArgs = "args"
+	// ParamRest параметр с текстом после префикса
+	ParamRest = "rest"
+	// ParamPayload параметр с данными ссылки t.me/bot?start=payload
+	ParamPayload = "payload"
+)
+
+// startCommand команда, которой Telegram передает данные ссылки t.me/bot?start=payload
+const startCommand = "/start"
+
+// Route маршрут к обработчику по шаблону. Маршруты создаются функциями
+// Command, Prefix, Regex и Pattern и проверяются в порядке объявления
+// после точного совпадения с MessageHandlers и CallbackHandlers.
+type Route struct {
+	Pattern string  // Шаблон маршрута, для описания и логов
+	Handler Handler // Обработчик
+	match   func(text string) (Params, bool)
+}
+
+// Match проверяет текст и возвращает извлеченные параметры
+func (r Route) Match(text string) (Params, bool) {
+	if r.match == nil {
+		return nil, false
+	}
+	return r.match(text)
+}
+
+// Command маршрут команды с аргументами: Command("/find", h) совпадает с "/find",
+// "/find ivan" и "/find@bot ivan". Команда сравнивается без учета регистра,
+// аргументы доступны в параметре ParamArgs.
+func Command(command string, handler Handler) Route {
+	command = strings.ToLower(command)
+	return Route{
+		Pattern: command,
+		Handler: handler,
+		match: func(text string) (Params, bool) {
+			name, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+			name, _, _ = strings.Cut(name, "@")
+			if strings.ToLower(name) != command {
+				return nil, false
+			}
+			return Params{ParamArgs: strings.TrimSpace(args)}, true
+		},
+	}
+}
+
+// Prefix маршрут по префиксу. Текст после префикса доступен в параметре ParamRest.
+func Prefix(prefix string, handler Handler) Route {
+	return Route{
+		Pattern: prefix + "*",
+		Handler: handler,
+		match: func(text string) (Params, bool) {
+			rest, ok := strings.CutPrefix(text, prefix)
+			if !ok {
+				return nil, false
+			}
+			return Params{ParamRest: rest}, true
+		},
+	}
+}
+
+// Regex маршрут по регулярному выражению. Именованные группы доступны в параметрах
+// по имени, неименованные - по номеру. Паникует при неверном выражении, как regexp.MustCompile.
+func Regex(expr string, handler Handler) Route {
+	re := regexp.MustCompile(expr)
+	return Route{
+		Pattern: expr,
+		Handler: handler,
+		match: func(text string) (Params, bool) {
+			match := re.FindStringSubmatch(text)
+			if match == nil {
+				return nil, false
+			}
+			params := make(Params, len(match)-1)
+			for i, name := range re.SubexpNames() {
+				if i == 0 {
+					continue
+				}
+				if name == "" {
+					name = strconv.Itoa(i)
+				}
+				params[name] = match[i]
+			}
+			return params, true
+		},
+	}
+}
+
+// DeepLink маршрут ссылки t.me/bot?start=payload с данными, начинающимися с prefix:
+// DeepLink("form_", h) совпадает с "/start form_12". Данные доступны в параметре ParamPayload,
+// часть после префикса - в ParamRest.
+func DeepLink(prefix string, handler Handler) Route {
+	start := Command(startCommand, handler)
+	return Route{
+		Pattern: startCommand + " " + prefix + "*",
+		Handler: handler,
+		match: func(text string) (Params, bool) {
+			params, ok := start.Match(text)
+			if !ok || params[ParamArgs] == "" {
+				return nil, false
+			}
+			payload := params[ParamArgs]
+			rest, ok := strings.CutPrefix(payload, prefix)
+			if !ok {
+				return nil, false
+			}
+			return Params{ParamPayload: payload, ParamRest: rest}, true
+		},
+	}
+}
+
+// StartPayload возвращает данные ссылки t.me/bot?start=payload из сообщения "/start payload"
+// или пустую строку
+func StartPayload(u tgbotapi.Update) string {
+	if u.Message == nil || !u.Message.IsCommand() || "/"+u.Message.Command() != startCommand {
+		return ""
+	}
+	return strings.TrimSpace(u.Message.CommandArguments())
+}
+
+// StartLink возвращает ссылку t.me/bot?start=payload, открывающую чат с ботом.
+// Telegram допускает в payload до 64 символов A-Z, a-z, 0-9, _ и -.
+func (app *Bot) StartLink(payload string) string {
+	return "https://t.me/" + app.BotAPI.Self.UserName + "?start=" + url.QueryEscape(payload)
+}
+
+// patternParamRegex параметр шаблона Pattern
+var patternParamRegex = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Pattern маршрут по шаблону с параметрами, например "form/{id}/status/{status}"
+// или "take:{id}". Параметр совпадает с непустым текстом без "/" и CallbackDataSeparator,
+// остальной шаблон должен совпасть полностью.
+func Pattern(pattern string, handler Handler) Route {
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range patternParamRegex.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		expr.WriteString("(?P<" + pattern[loc[2]:loc[3]] + ">[^/" + regexp.QuoteMeta(CallbackDataSeparator) + "]+)")
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+
+	route := Regex(expr.String(), handler)
+	route.Pattern = pattern
+	return route
+}
+
+// matchRoute возвращает первый подходящий маршрут
+func matchRoute(routes []Route, text string) (*Route, Params, bool) {
+	for i := range routes {
+		if params, ok := routes[i].Match(text); ok {
+			return &routes[i], params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// handleRoute вызывает обработчик маршрута. Параметры передаются в Handler.HandleRoute
+// при каждом вызове, поэтому вложенные и параллельные обработчики не видят чужих параметров.
+func (app *Bot) handleRoute(userState *State, route *Route, params Params, update tgbotapi.Update) error {
+	handle := route.Handler.Handle
+	if route.Handler.HandleRoute != nil {
+		handle = func(b *Bot, u tgbotapi.Update) error {
+			return route.Handler.HandleRoute(b, u, params)
+		}
+	}
+	return app.wrap(userState, handle)(app, update)
+}
@@ -0,0 +1,255 @@
+package tg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRouteMatch(t *testing.T) {
+	noop := Handler{Handle: func(*Bot, tgbotapi.Update) error { return nil }}
+
+	tests := []struct {
+		name   string
+		route  Route
+		text   string
+		ok     bool
+		params Params
+	}{
+		{name: "command", route: Command("/find", noop), text: "/find", ok: true, params: Params{ParamArgs: ""}},
+		{name: "command args", route: Command("/find", noop), text: "/find  ivan petrov ", ok: true, params: Params{ParamArgs: "ivan petrov"}},
+		{name: "command mention", route: Command("/find", noop), text: "/find@nstu_bot ivan", ok: true, params: Params{ParamArgs: "ivan"}},
+		{name: "command case", route: Command("/Find", noop), text: "/FIND ivan", ok: true, params: Params{ParamArgs: "ivan"}},
+		{name: "command other", route: Command("/find", noop), text: "/finder", ok: false},
+		{name: "command text", route: Command("/find", noop), text: "find ivan", ok: false},
+
+		{name: "prefix", route: Prefix("form_", noop), text: "form_12", ok: true, params: Params{ParamRest: "12"}},
+		{name: "prefix only", route: Prefix("form_", noop), text: "form_", ok: true, params: Params{ParamRest: ""}},
+		{name: "prefix other", route: Prefix("form_", noop), text: "src_12", ok: false},
+
+		{name: "regex named", route: Regex(`^take:(?P<id>\d+)$`, noop), text: "take:15", ok: true, params: Params{"id": "15"}},
+		{name: "regex numbered", route: Regex(`^(\w+)-(\d+)$`, noop), text: "form-3", ok: true, params: Params{"1": "form", "2": "3"}},
+		{name: "regex miss", route: Regex(`^take:(?P<id>\d+)$`, noop), text: "take:abc", ok: false},
+
+		{name: "pattern", route: Pattern("form/{id}/status/{status}", noop), text: "form/7/status/resolved", ok: true, params: Params{"id": "7", "status": "resolved"}},
+		{name: "pattern callback", route: Pattern("form_take:{id}", noop), text: "form_take:7", ok: true, params: Params{"id": "7"}},
+		{name: "pattern empty param", route: Pattern("form_take:{id}", noop), text: "form_take:", ok: false},
+		{name: "pattern separator in param", route: Pattern("form_take:{id}", noop), text: "form_take:7:8", ok: false},
+		{name: "pattern slash in param", route: Pattern("form/{id}", noop), text: "form/7/8", ok: false},
+		{name: "pattern extra text", route: Pattern("form/{id}", noop), text: "my form/7", ok: false},
+		{name: "pattern meta characters", route: Pattern("a.b({id})", noop), text: "a.b(5)", ok: true, params: Params{"id": "5"}},
+		{name: "pattern meta miss", route: Pattern("a.b({id})", noop), text: "axb(5)", ok: false},
+
+		{name: "deep link", route: DeepLink("form_", noop), text: "/start form_12", ok: true, params: Params{ParamPayload: "form_12", ParamRest: "12"}},
+		{name: "deep link mention", route: DeepLink("src_", noop), text: "/start@nstu_bot src_vk", ok: true, params: Params{ParamPayload: "src_vk", ParamRest: "vk"}},
+		{name: "deep link other prefix", route: DeepLink("form_", noop), text: "/start src_vk", ok: false},
+		{name: "deep link without payload", route: DeepLink("form_", noop), text: "/start", ok: false},
+		{name: "deep link other command", route: DeepLink("form_", noop), text: "/find form_12", ok: false},
+
+		{name: "zero route", route: Route{}, text: "anything", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, ok := tt.route.Match(tt.text)
+			if ok != tt.ok {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("Match(%q) params = %v, want %v", tt.text, params, tt.params)
+			}
+		})
+	}
+}
+
+func TestMatchRouteOrder(t *testing.T) {
+	noop := Handler{Handle: func(*Bot, tgbotapi.Update) error { return nil }}
+	routes := []Route{
+		Pattern("form/{id}", noop),
+		Prefix("form/", noop),
+	}
+
+	route, params, ok := matchRoute(routes, "form/7")
+	if !ok || route.Pattern != "form/{id}" || params.Get("id") != "7" {
+		t.Errorf("form/7 matched %v with %v", route, params)
+	}
+	route, params, ok = matchRoute(routes, "form/")
+	if !ok || route.Pattern != "form/*" || params.Get(ParamRest) != "" {
+		t.Errorf("form/ matched %v with %v", route, params)
+	}
+	if _, _, ok := matchRoute(routes, "menu"); ok {
+		t.Error("menu must not match")
+	}
+}
+
+func TestParams(t *testing.T) {
+	params := Params{"id": "42", "bad": "x", ParamArgs: " ivan  petrov "}
+
+	if id, err := params.Int64("id"); err != nil || id != 42 {
+		t.Errorf("Int64(id) = %d, %v", id, err)
+	}
+	if _, err := params.Int64("bad"); err == nil {
+		t.Error("Int64(bad) must fail")
+	}
+	if got := params.Get("missing"); got != "" {
+		t.Errorf("Get(missing) = %q", got)
+	}
+	if got := params.Args(); !reflect.DeepEqual(got, []string{"ivan", "petrov"}) {
+		t.Errorf("Args() = %v", got)
+	}
+}
+
+func TestRouteParamsInHandler(t *testing.T) {
+	var got Params
+	states := map[string]State{
+		"start": {
+			Global: true,
+			MessageRoutes: []Route{
+				Command("/find", Handler{HandleRoute: func(b *Bot, u tgbotapi.Update, params Params) error {
+					got = params
+					return nil
+				}}),
+			},
+		},
+	}
+	b := newTestBot(t, Config{States: states})
+
+	b.processUpdate(textUpdate(1, 42, "/find ivan"))
+	if got.Get(ParamArgs) != "ivan" {
+		t.Errorf("handler params = %v", got)
+	}
+}
+
+func TestNestedRouteParams(t *testing.T) {
+	var outer, inner Params
+	states := map[string]State{
+		"start": {
+			Global: true,
+			MessageRoutes: []Route{
+				Command("/outer", Handler{HandleRoute: func(b *Bot, u tgbotapi.Update, params Params) error {
+					// Вложенное обновление с тем же ID не должно затирать параметры внешнего
+					b.processUpdate(textUpdate(u.UpdateID, 43, "/inner petr"))
+					outer = params
+					return nil
+				}}),
+				Command("/inner", Handler{HandleRoute: func(b *Bot, u tgbotapi.Update, params Params) error {
+					inner = params
+					return nil
+				}}),
+			},
+		},
+	}
+	b := newTestBot(t, Config{States: states})
+
+	b.processUpdate(textUpdate(0, 42, "/outer ivan"))
+	if outer.Get(ParamArgs) != "ivan" || inner.Get(ParamArgs) != "petr" {
+		t.Errorf("outer params = %v, inner params = %v", outer, inner)
+	}
+}
+
+func TestRouteWithoutHandleRoute(t *testing.T) {
+	handled := false
+	states := map[string]State{
+		"start": {
+			Global: true,
+			MessageRoutes: []Route{
+				Command("/find", Handler{Handle: func(b *Bot, u tgbotapi.Update) error {
+					handled = true
+					return nil
+				}}),
+			},
+		},
+	}
+	b := newTestBot(t, Config{States: states})
+
+	b.processUpdate(textUpdate(1, 42, "/find ivan"))
+	if !handled {
+		t.Error("Handle was not called for route without HandleRoute")
+	}
+}
+
+// commandUpdate сообщение с командой, как его присылает Telegram: с сущностью bot_command
+func commandUpdate(updateID int, userID int64, text string) tgbotapi.Update {
+	u := textUpdate(updateID, userID, text)
+	name, _, _ := strings.Cut(text, " ")
+	u.Message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name)}}
+	return u
+}
+
+func TestStartPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   string
+	}{
+		{name: "payload", update: commandUpdate(1, 42, "/start form_12"), want: "form_12"},
+		{name: "mention", update: commandUpdate(1, 42, "/start@nstu_bot src_vk"), want: "src_vk"},
+		{name: "without payload", update: commandUpdate(1, 42, "/start"), want: ""},
+		{name: "other command", update: commandUpdate(1, 42, "/find form_12"), want: ""},
+		{name: "not a command", update: textUpdate(1, 42, "/start form_12"), want: ""},
+		{name: "callback", update: tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{Data: "/start form_12"}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StartPayload(tt.update); got != tt.want {
+				t.Errorf("StartPayload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartLink(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	if got, want := b.StartLink("form_12"), "https://t.me/test_bot?start=form_12"; got != want {
+		t.Errorf("StartLink() = %q, want %q", got, want)
+	}
+}
+
+func TestDeepLinkDispatch(t *testing.T) {
+	var handled, payload string
+	handler := func(name string) Handler {
+		return Handler{
+			Handle: func(b *Bot, u tgbotapi.Update) error {
+				handled, payload = name, StartPayload(u)
+				return nil
+			},
+			HandleRoute: func(b *Bot, u tgbotapi.Update, params Params) error {
+				handled, payload = name, params.Get(ParamRest)
+				return nil
+			},
+		}
+	}
+	states := map[string]State{
+		"start": {
+			Global:          true,
+			MessageHandlers: map[string]Handler{"/start": handler("start")},
+			MessageRoutes:   []Route{DeepLink("form_", handler("form"))},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		text        string
+		wantHandler string
+		wantPayload string
+	}{
+		{name: "start", text: "/start", wantHandler: "start", wantPayload: ""},
+		{name: "deep link route", text: "/start form_12", wantHandler: "form", wantPayload: "12"},
+		// Данные без маршрута DeepLink обрабатывает "/start"
+		{name: "unknown payload", text: "/start src_vk", wantHandler: "start", wantPayload: "src_vk"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, payload = "", ""
+			b := newTestBot(t, Config{States: states})
+			b.processUpdate(commandUpdate(i+1, 42, tt.text))
+			if handled != tt.wantHandler || payload != tt.wantPayload {
+				t.Errorf("handled by %q with payload %q, want %q with %q", handled, payload, tt.wantHandler, tt.wantPayload)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Send отправляет сообщение любого типа: текст, фото, документ, стикер и т.д.
+// Запрос ждет слота в лимитере чата и повторяется по правилам Config.Retry.
+// Ошибки Telegram возвращаются как *APIError.
+func (app *Bot) Send(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return app.send(ctx, c, app.retryPolicy.MaxAttempts)
+}
+
+// Request выполняет запрос, результат которого не сообщение: удаление, закрепление,
+// действие в чате, ответ на callback и т.д. Лимиты и повторы как у Send.
+func (app *Bot) Request(ctx context.Context, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return app.request(ctx, c, app.retryPolicy.MaxAttempts)
+}
+
+// SendMediaGroup отправляет альбом и возвращает все отправленные сообщения
+func (app *Bot) SendMediaGroup(ctx context.Context, c tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	resp, err := app.Request(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode media group: %w", err)
+	}
+	return messages, nil
+}
+
+// send отправляет сообщение, делая до attempts попыток
+func (app *Bot) send(ctx context.Context, c tgbotapi.Chattable, attempts int) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	err := app.do(ctx, c, attempts, func() (err error) {
+		msg, err = app.BotAPI.Send(c)
+		return err
+	})
+	return msg, err
+}
+
+// request выполняет запрос, делая до attempts попыток
+func (app *Bot) request(ctx context.Context, c tgbotapi.Chattable, attempts int) (*tgbotapi.APIResponse, error) {
+	var resp *tgbotapi.APIResponse
+	err := app.do(ctx, c, attempts, func() (err error) {
+		resp, err = app.BotAPI.Request(c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// do выполняет call для запроса c: ждет слота в лимитере, повторяет по RetryPolicy и пишет ошибку в лог
+func (app *Bot) do(ctx context.Context, c tgbotapi.Chattable, attempts int, call func() error) error {
+	chatID, message := messageChat(c)
+	err := app.retry(ctx, attempts, func() error {
+		var err error
+		if message {
+			err = app.limiter.Wait(ctx, chatID)
+		} else {
+			err = app.limiter.WaitAPI(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		return call()
+	})
+	if err != nil {
+		event := app.logger.Error()
+		if errors.Is(err, ErrMessageNotModified) {
+			// Не ошибка: сообщение уже в нужном виде
+			event = app.logger.Debug()
+		}
+		event.
+			Str("request", fmt.Sprintf("%T", c)).
+			Int64("chat_id", chatID).
+			Err(err).
+			Msg("telegram request failed")
+	}
+	return err
+}
+
+// makeRequest выполняет запрос к методу API, которого нет в tgbotapi. Лимиты и повторы как у Request.
+func (app *Bot) makeRequest(ctx context.Context, method string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	var resp *tgbotapi.APIResponse
+	err := app.retry(ctx, app.retryPolicy.MaxAttempts, func() error {
+		if err := app.limiter.WaitAPI(ctx); err != nil {
+			return err
+		}
+		var err error
+		resp, err = app.BotAPI.MakeRequest(method, params)
+		return err
+	})
+	if err != nil {
+		app.logger.Error().Str("request", method).Err(err).Msg("telegram request failed")
+		return nil, err
+	}
+	return resp, nil
+}
+
+// messageChat возвращает чат, в который запрос отправляет новое сообщение.
+// Такие запросы расходуют бюджет чата в лимитере, остальные - только общий.
+// Все конфигурации отправки сообщений tgbotapi встраивают BaseChat.
+func messageChat(c tgbotapi.Chattable) (int64, bool) {
+	switch c := c.(type) {
+	case tgbotapi.ChatActionConfig:
+		return c.ChatID, false
+	case tgbotapi.MediaGroupConfig:
+		return c.ChatID, true
+	}
+
+	v := reflect.ValueOf(c)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	base := v.FieldByName("BaseChat")
+	if !base.IsValid() {
+		return 0, false
+	}
+	return base.Interface().(tgbotapi.BaseChat).ChatID, true
+}
@@ -0,0 +1,139 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestMessageChat(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      tgbotapi.Chattable
+		wantChat    int64
+		wantMessage bool
+	}{
+		{name: "text", config: tgbotapi.NewMessage(42, "hi"), wantChat: 42, wantMessage: true},
+		{name: "text pointer", config: &tgbotapi.MessageConfig{BaseChat: tgbotapi.BaseChat{ChatID: 42}}, wantChat: 42, wantMessage: true},
+		{name: "photo", config: tgbotapi.NewPhoto(42, tgbotapi.FileID("p")), wantChat: 42, wantMessage: true},
+		{name: "document", config: tgbotapi.NewDocument(-100200, tgbotapi.FileID("d")), wantChat: -100200, wantMessage: true},
+		{name: "media group", config: tgbotapi.NewMediaGroup(42, []interface{}{tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("p"))}), wantChat: 42, wantMessage: true},
+		{name: "chat action", config: tgbotapi.NewChatAction(42, tgbotapi.ChatTyping), wantChat: 42},
+		{name: "edit", config: tgbotapi.NewEditMessageText(42, 1, "edited"), wantChat: 0},
+		{name: "callback answer", config: tgbotapi.NewCallback("q", "ok"), wantChat: 0},
+		{name: "delete", config: tgbotapi.NewDeleteMessage(42, 1), wantChat: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatID, message := messageChat(tt.config)
+			if chatID != tt.wantChat || message != tt.wantMessage {
+				t.Errorf("messageChat() = %d, %v, want %d, %v", chatID, message, tt.wantChat, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	sent := tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: 42}, Text: "hi"}
+
+	tests := []struct {
+		name      string
+		responses []tgbotapi.APIResponse // Ответы на попытки по порядку
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "sent", responses: []tgbotapi.APIResponse{okResponse(sent)}, wantCalls: 1},
+		{name: "retry server error", responses: []tgbotapi.APIResponse{errorResponse(502, "Bad Gateway", nil), okResponse(sent)}, wantCalls: 2},
+		{name: "chat not found", responses: []tgbotapi.APIResponse{chatNotFound}, wantCalls: 1, wantErr: ErrChatNotFound},
+		{name: "bot blocked", responses: []tgbotapi.APIResponse{errorResponse(403, "Forbidden: bot was blocked by the user", nil)}, wantCalls: 1, wantErr: ErrBotBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}, Retry: RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}})
+			calls := 0
+			api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse {
+				calls++
+				return tt.responses[min(calls, len(tt.responses))-1]
+			})
+
+			msg, err := b.Send(context.Background(), tgbotapi.NewMessage(42, "hi"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Errorf("Send() error %T is not *APIError", err)
+				}
+			} else if msg.MessageID != sent.MessageID {
+				t.Errorf("Send() message = %d, want %d", msg.MessageID, sent.MessageID)
+			}
+			if got := api.chatIDs("sendMessage"); len(got) != tt.wantCalls {
+				t.Errorf("sendMessage called %d times, want %d", len(got), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	resp, err := b.Request(context.Background(), tgbotapi.NewDeleteMessage(42, 7))
+	if err != nil || !resp.Ok {
+		t.Fatalf("Request() = %+v, %v", resp, err)
+	}
+	if len(api.calls) != 1 || api.calls[0].method != "deleteMessage" || api.calls[0].params.Get("message_id") != "7" {
+		t.Errorf("calls = %+v", api.calls)
+	}
+}
+
+func TestSendMediaGroup(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	withFakeAPI(b, func(apiCall) tgbotapi.APIResponse {
+		return okResponse([]tgbotapi.Message{{MessageID: 1}, {MessageID: 2}})
+	})
+
+	group := tgbotapi.NewMediaGroup(42, []interface{}{
+		tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("p1")),
+		tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("p2")),
+	})
+	messages, err := b.SendMediaGroup(context.Background(), group)
+	if err != nil {
+		t.Fatalf("SendMediaGroup() error = %v", err)
+	}
+	ids := make([]int, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.MessageID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("message ids = %v", ids)
+	}
+}
+
+func TestMakeRequest(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}, Retry: RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}})
+	calls := 0
+	api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse {
+		calls++
+		if calls == 1 {
+			return errorResponse(500, "Internal Server Error", nil)
+		}
+		return okResponse(true)
+	})
+
+	params := tgbotapi.Params{"chat_id": "42"}
+	if _, err := b.makeRequest(context.Background(), "getChatMenuButton", params); err != nil {
+		t.Fatalf("makeRequest() error = %v", err)
+	}
+	// Запросы к методам без конфигурации в tgbotapi повторяются так же, как Send
+	if got := api.methods(); !reflect.DeepEqual(got, []string{"getChatMenuButton", "getChatMenuButton"}) {
+		t.Errorf("methods = %v", got)
+	}
+}
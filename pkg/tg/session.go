@@ -0,0 +1,103 @@
+package tg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// entryLockStripes количество блокировок для изменения записей пользователей.
+// Запись читается и сохраняется целиком, поэтому изменения одного пользователя сериализуются.
+const entryLockStripes = 64
+
+// Session данные пользователя между сообщениями, например ответы в многошаговой форме.
+// Хранятся в StateStore вместе с состоянием пользователя и истекают вместе с ним:
+// каждое изменение состояния или данных продлевает запись на Config.Expiration.
+// Значения сериализуются в JSON.
+type Session struct {
+	bot    *Bot
+	userID int64
+}
+
+// Session возвращает сессию пользователя
+func (app *Bot) Session(userID int64) *Session {
+	return &Session{bot: app, userID: userID}
+}
+
+// Get записывает значение по ключу в dst. Возвращает false, если значения нет.
+func (s *Session) Get(key string, dst any) (bool, error) {
+	entry, err := s.bot.store.Get(s.userID)
+	if errors.Is(err, ErrStateNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok := entry.Data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return false, fmt.Errorf("failed to decode session value %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set сохраняет значение по ключу
+func (s *Session) Set(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode session value %q: %w", key, err)
+	}
+
+	return s.bot.updateEntry(s.userID, func(entry *StateEntry) {
+		if entry.Data == nil {
+			entry.Data = make(map[string]json.RawMessage)
+		}
+		entry.Data[key] = raw
+	})
+}
+
+// Delete удаляет значение по ключу
+func (s *Session) Delete(key string) error {
+	return s.bot.updateEntry(s.userID, func(entry *StateEntry) {
+		delete(entry.Data, key)
+	})
+}
+
+// Clear удаляет все данные сессии, состояние пользователя сохраняется
+func (s *Session) Clear() error {
+	return s.bot.updateEntry(s.userID, func(entry *StateEntry) {
+		entry.Data = nil
+	})
+}
+
+// SessionValue возвращает значение сессии с типом T. Возвращает false, если значения нет.
+func SessionValue[T any](s *Session, key string) (T, bool, error) {
+	var value T
+	ok, err := s.Get(key, &value)
+	return value, ok, err
+}
+
+// updateEntry изменяет запись пользователя и сохраняет ее на время expiration.
+// Пустая запись удаляется из хранилища.
+func (app *Bot) updateEntry(userID int64, update func(entry *StateEntry)) error {
+	mu := &app.entryLocks[uint64(userID)%entryLockStripes]
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry, err := app.store.Get(userID)
+	if errors.Is(err, ErrStateNotFound) {
+		entry = &StateEntry{}
+	} else if err != nil {
+		return err
+	}
+
+	update(entry)
+
+	if entry.State == "" && len(entry.Data) == 0 {
+		return app.store.Delete(userID)
+	}
+	return app.store.Set(userID, entry, app.expiration)
+}
@@ -0,0 +1,164 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	type form struct {
+		Category string `json:"category"`
+		Step     int    `json:"step"`
+	}
+
+	tests := []struct {
+		name   string
+		run    func(s *Session) error
+		key    string
+		want   form
+		wantOK bool
+	}{
+		{
+			name: "missing",
+			run:  func(*Session) error { return nil },
+			key:  "form",
+		},
+		{
+			name:   "set",
+			run:    func(s *Session) error { return s.Set("form", form{Category: "dorm", Step: 2}) },
+			key:    "form",
+			want:   form{Category: "dorm", Step: 2},
+			wantOK: true,
+		},
+		{
+			name: "other key",
+			run:  func(s *Session) error { return s.Set("draft", form{Step: 1}) },
+			key:  "form",
+		},
+		{
+			name: "delete",
+			run: func(s *Session) error {
+				if err := s.Set("form", form{Step: 1}); err != nil {
+					return err
+				}
+				return s.Delete("form")
+			},
+			key: "form",
+		},
+		{
+			name: "clear",
+			run: func(s *Session) error {
+				if err := s.Set("form", form{Step: 1}); err != nil {
+					return err
+				}
+				if err := s.Set("draft", form{Step: 2}); err != nil {
+					return err
+				}
+				return s.Clear()
+			},
+			key: "draft",
+		},
+		{
+			name: "delete missing key",
+			run:  func(s *Session) error { return s.Delete("form") },
+			key:  "form",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			session := b.Session(42)
+			if err := tt.run(session); err != nil {
+				t.Fatalf("setup failed: %v", err)
+			}
+			got, ok, err := SessionValue[form](session, tt.key)
+			if err != nil {
+				t.Fatalf("SessionValue() error = %v", err)
+			}
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("SessionValue() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSessionDecodeError(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	session := b.Session(42)
+	if err := session.Set("step", "two"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, ok, err := SessionValue[int](session, "step"); err == nil || ok {
+		t.Errorf("SessionValue[int] = %v, %v, want decode error", ok, err)
+	}
+	if err := session.Set("bad", func() {}); err == nil {
+		t.Error("Set must fail for values without JSON encoding")
+	}
+}
+
+func TestSessionAndState(t *testing.T) {
+	states := map[string]State{
+		"start":     {Global: true, Transitions: []string{"form_text"}},
+		"form_text": {Context: true},
+	}
+	b := newTestBot(t, Config{States: states})
+	session := b.Session(42)
+
+	if err := session.Set("category", "dorm"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	// Смена состояния сохраняет данные сессии
+	b.SetUserState(42, "form_text", false, nil)
+	if category, ok, _ := SessionValue[string](session, "category"); !ok || category != "dorm" {
+		t.Errorf("category = %q, %v after state change", category, ok)
+	}
+
+	// Очистка сессии сохраняет состояние
+	if err := session.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if state, err := b.GetUserState(42); err != nil || state != "form_text" {
+		t.Errorf("GetUserState() = %q, %v after Clear", state, err)
+	}
+}
+
+func TestSessionEmptyEntryDeleted(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	session := b.Session(42)
+	if err := session.Set("category", "dorm"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := session.Delete("category"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := b.store.Get(42); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("store.Get() error = %v, want %v", err, ErrStateNotFound)
+	}
+}
+
+func TestSessionConcurrentSet(t *testing.T) {
+	b := newTestBot(t, Config{States: map[string]State{}})
+	session := b.Session(42)
+
+	const keys = 50
+	var wg sync.WaitGroup
+	for i := 0; i < keys; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := session.Set(fmt.Sprintf("key%d", i), i); err != nil {
+				t.Errorf("Set failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Изменения одного пользователя не теряются при одновременной записи
+	entry, err := b.store.Get(42)
+	if err != nil || len(entry.Data) != keys {
+		t.Errorf("stored %d keys, %v; want %d", len(entry.Data), err, keys)
+	}
+}
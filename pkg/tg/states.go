@@ -1,17 +1,36 @@
 package tg
 
 import (
+	"strings"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// CallbackDataSeparator разделяет ключ обработчика и аргументы в данных callback.
+// Данные "take:123" обрабатываются обработчиком из CallbackHandlers с ключом "take",
+// если обработчика с ключом "take:123" нет.
+const CallbackDataSeparator = ":"
+
 type HandlerFunc func(b *Bot, u tgbotapi.Update) error
 
+// RouteFunc обработчик маршрута, получающий параметры, извлеченные маршрутом
+type RouteFunc func(b *Bot, u tgbotapi.Update, params Params) error
+
 type Handler struct {
 	// Handle обрабатывает входящее обновление от Telegram.
 	Handle HandlerFunc
 
+	// HandleRoute обрабатывает обновление, совпавшее с маршрутом Route, и получает его параметры.
+	// Если задан, используется в маршрутах вместо Handle.
+	HandleRoute RouteFunc
+
 	// Description возвращает описание обработчика.
+	// Для команд вида "/name" описание показывается в меню команд и в /help.
 	Description string
+
+	// Descriptions описания команды на других языках по коду языка, например "en".
+	// Для языков без перевода используется Description.
+	Descriptions map[string]string
 }
 
 // State представляет состояние бота и определяет правила обработки сообщений.
@@ -26,6 +45,45 @@ type State struct {
 	CatchAllFunc     *Handler           // Выполняется для всех событий, которые не попали в маршруты. В глобальных состояниях следует использовать аккуратнее.
 	MessageHandlers  map[string]Handler // Сопоставляет текст сообщения с обработчиком
 	CallbackHandlers map[string]Handler // Сопоставляет данные callback с обработчиком
+	MessageRoutes    []Route            // Маршруты сообщений по шаблону, проверяются после MessageHandlers
+	CallbackRoutes   []Route            // Маршруты callback по шаблону, проверяются после CallbackHandlers
+	Middlewares      []Middleware       // Оборачивают обработчики состояния, выполняются после глобальных middleware
+	Transitions      []string           // Состояния, в которые переводят обработчики состояния. Проверяются в NewBot
+
+	// Обработчики обновлений без текста. Если обработчик для сообщения не задан,
+	// оно обрабатывается как текстовое: MessageHandlers, MessageRoutes и CatchAllFunc.
+	ContactHandler         *Handler // Сообщение с контактом, например от кнопки запроса номера телефона
+	MediaHandler           *Handler // Фото, документ, видео, аудио или голосовое сообщение
+	LocationHandler        *Handler // Сообщение с геопозицией
+	WebAppDataHandler      *Handler // Данные Mini App (web_app_data), доступны через Bot.WebAppData
+	InlineQueryHandler     *Handler // Inline запрос
+	ChatMemberHandler      *Handler // Изменение участника чата или статуса бота в чате (chat_member, my_chat_member)
+	ChatJoinRequestHandler *Handler // Заявка на вступление в чат
+}
+
+// kindHandler возвращает обработчик состояния для типа обновления или nil
+func (s *State) kindHandler(b *Bot, u tgbotapi.Update) *Handler {
+	switch {
+	case u.Message != nil:
+		msg := u.Message
+		switch {
+		case msg.Contact != nil:
+			return s.ContactHandler
+		case b.WebAppData(u) != nil:
+			return s.WebAppDataHandler
+		case msg.Location != nil:
+			return s.LocationHandler
+		case len(msg.Photo) > 0 || msg.Document != nil || msg.Video != nil || msg.Audio != nil || msg.Voice != nil:
+			return s.MediaHandler
+		}
+	case u.InlineQuery != nil:
+		return s.InlineQueryHandler
+	case u.MyChatMember != nil || u.ChatMember != nil:
+		return s.ChatMemberHandler
+	case u.ChatJoinRequest != nil:
+		return s.ChatJoinRequestHandler
+	}
+	return nil
 }
 
 // NewState создает новый экземпляр State с заданными параметрами.
@@ -39,3 +97,20 @@ func NewState(global bool, context bool, atEntranceFunc *Handler, catchAllFunc *
 		CallbackHandlers: make(map[string]Handler),
 	}
 }
+
+// NewCallbackData формирует данные callback из ключа обработчика и аргументов
+func NewCallbackData(key string, args ...string) string {
+	return strings.Join(append([]string{key}, args...), CallbackDataSeparator)
+}
+
+// CallbackKey возвращает ключ обработчика из данных callback
+func CallbackKey(data string) string {
+	key, _, _ := strings.Cut(data, CallbackDataSeparator)
+	return key
+}
+
+// CallbackArgs возвращает аргументы из данных callback
+func CallbackArgs(data string) []string {
+	parts := strings.Split(data, CallbackDataSeparator)
+	return parts[1:]
+}
@@ -0,0 +1,169 @@
+package tg
+
+import (
+	"reflect"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestCallbackData(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		args []string
+		data string
+	}{
+		{name: "key only", key: "menu", args: []string{}, data: "menu"},
+		{name: "one arg", key: "form_take", args: []string{"12"}, data: "form_take:12"},
+		{name: "several args", key: "page", args: []string{"forms", "3"}, data: "page:forms:3"},
+		{name: "empty arg", key: "form_take", args: []string{""}, data: "form_take:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewCallbackData(tt.key, tt.args...); got != tt.data {
+				t.Errorf("NewCallbackData() = %q, want %q", got, tt.data)
+			}
+			if got := CallbackKey(tt.data); got != tt.key {
+				t.Errorf("CallbackKey(%q) = %q, want %q", tt.data, got, tt.key)
+			}
+			if got := CallbackArgs(tt.data); !reflect.DeepEqual(got, tt.args) {
+				t.Errorf("CallbackArgs(%q) = %q, want %q", tt.data, got, tt.args)
+			}
+		})
+	}
+}
+
+// callbackUpdate нажатие кнопки с данными data пользователем userID в личном чате
+func callbackUpdate(updateID int, userID int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "query",
+			From:    &tgbotapi.User{ID: userID, FirstName: "Ivan"},
+			Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: userID, Type: "private"}},
+			Data:    data,
+		},
+	}
+}
+
+func TestCallbackHandlerByKey(t *testing.T) {
+	var handled string
+	handler := func(name string) Handler {
+		return Handler{Handle: func(b *Bot, u tgbotapi.Update) error {
+			handled = name
+			return nil
+		}}
+	}
+	states := map[string]State{
+		"start": {
+			Global: true,
+			CallbackHandlers: map[string]Handler{
+				"take":    handler("take"),
+				"take:42": handler("take 42"),
+			},
+		},
+	}
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: "take", want: "take"},
+		{data: "take:12", want: "take"},
+		{data: "take:12:extra", want: "take"},
+		{data: "take:42", want: "take 42"}, // Точное совпадение важнее ключа
+		{data: "resolve:12", want: ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			handled = ""
+			b := newTestBot(t, Config{States: states})
+			withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+			b.processUpdate(callbackUpdate(i+1, 42, tt.data))
+			if handled != tt.want {
+				t.Errorf("handled by %q, want %q", handled, tt.want)
+			}
+		})
+	}
+}
+
+func TestKindHandlers(t *testing.T) {
+	var handled string
+	record := func(name string) *Handler {
+		return &Handler{Handle: func(*Bot, tgbotapi.Update) error {
+			handled = name
+			return nil
+		}}
+	}
+	all := State{
+		Global:                 true,
+		ContactHandler:         record("contact"),
+		MediaHandler:           record("media"),
+		LocationHandler:        record("location"),
+		WebAppDataHandler:      record("web_app_data"),
+		InlineQueryHandler:     record("inline_query"),
+		ChatMemberHandler:      record("chat_member"),
+		ChatJoinRequestHandler: record("chat_join_request"),
+		CatchAllFunc:           record("catch-all"),
+	}
+	// Без обработчика типа обновление уходит в CatchAllFunc
+	catchAll := State{Global: true, CatchAllFunc: record("catch-all")}
+
+	user := tgbotapi.User{ID: 42, FirstName: "Ivan"}
+	chat := tgbotapi.Chat{ID: -100200, Type: "supergroup"}
+	message := func(edit func(m *tgbotapi.Message)) tgbotapi.Update {
+		u := textUpdate(1, 42, "")
+		edit(u.Message)
+		return u
+	}
+
+	tests := []struct {
+		name   string
+		state  State
+		update tgbotapi.Update
+		raw    string // Обновление в JSON, если нужны поля, которых нет в tgbotapi.Update
+		want   string
+	}{
+		{name: "contact", state: all, update: message(func(m *tgbotapi.Message) { m.Contact = &tgbotapi.Contact{PhoneNumber: "+79990000000"} }), want: "contact"},
+		{name: "photo", state: all, update: message(func(m *tgbotapi.Message) { m.Photo = []tgbotapi.PhotoSize{{FileID: "p"}} }), want: "media"},
+		{name: "document", state: all, update: message(func(m *tgbotapi.Message) { m.Document = &tgbotapi.Document{FileID: "d"} }), want: "media"},
+		{name: "voice", state: all, update: message(func(m *tgbotapi.Message) { m.Voice = &tgbotapi.Voice{FileID: "v"} }), want: "media"},
+		{name: "location", state: all, update: message(func(m *tgbotapi.Message) { m.Location = &tgbotapi.Location{Latitude: 55, Longitude: 83} }), want: "location"},
+		{
+			name:  "web app data",
+			state: all,
+			raw:   `{"update_id":1,"message":{"message_id":1,"from":{"id":42},"chat":{"id":42,"type":"private"},"web_app_data":{"data":"{}","button_text":"Form"}}}`,
+			want:  "web_app_data",
+		},
+		{name: "inline query", state: all, update: tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q", From: &user}}, want: "inline_query"},
+		{name: "my chat member", state: all, update: tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{From: user, Chat: chat}}, want: "chat_member"},
+		{name: "chat member", state: all, update: tgbotapi.Update{ChatMember: &tgbotapi.ChatMemberUpdated{From: user, Chat: chat}}, want: "chat_member"},
+		{name: "join request", state: all, update: tgbotapi.Update{ChatJoinRequest: &tgbotapi.ChatJoinRequest{From: user, Chat: chat}}, want: "chat_join_request"},
+		{name: "text", state: all, update: textUpdate(1, 42, "hello"), want: "catch-all"},
+		{name: "contact without handler", state: catchAll, update: message(func(m *tgbotapi.Message) { m.Contact = &tgbotapi.Contact{PhoneNumber: "+79990000000"} }), want: "catch-all"},
+		{name: "inline query without handler", state: catchAll, update: tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q", From: &user}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = ""
+			b := newTestBot(t, Config{States: map[string]State{"start": tt.state}})
+			withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+			update := tt.update
+			if tt.raw != "" {
+				var err error
+				if update, err = b.decodeUpdate([]byte(tt.raw)); err != nil {
+					t.Fatalf("decodeUpdate failed: %v", err)
+				}
+			}
+			b.processUpdate(update)
+			if handled != tt.want {
+				t.Errorf("handled by %q, want %q", handled, tt.want)
+			}
+		})
+	}
+}
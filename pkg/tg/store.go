@@ -0,0 +1,96 @@
+package tg
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// StateEntry запись хранилища: состояние пользователя и данные его сессии.
+// Состояние и данные хранятся и истекают вместе.
+type StateEntry struct {
+	State string                     `json:"state"`          // Название состояния, пустое - состояние не задано
+	Data  map[string]json.RawMessage `json:"data,omitempty"` // Данные сессии в JSON
+}
+
+// clone возвращает копию записи, не разделяющую Data с исходной
+func (e *StateEntry) clone() *StateEntry {
+	c := &StateEntry{State: e.State}
+	if len(e.Data) > 0 {
+		c.Data = make(map[string]json.RawMessage, len(e.Data))
+		for k, v := range e.Data {
+			c.Data[k] = v
+		}
+	}
+	return c
+}
+
+// StateStore хранилище состояний пользователей.
+// ttl <= 0 означает, что запись хранится бессрочно.
+// Реализации должны быть безопасны для конкурентного использования.
+type StateStore interface {
+	// Get возвращает запись пользователя или ErrStateNotFound, если ее нет или она истекла
+	Get(userID int64) (*StateEntry, error)
+
+	// Set сохраняет запись пользователя на время ttl
+	Set(userID int64, entry *StateEntry, ttl time.Duration) error
+
+	// Delete удаляет запись пользователя
+	Delete(userID int64) error
+}
+
+// ExpiringStore хранилище, из которого нужно периодически удалять истекшие записи.
+// Бот вызывает DeleteExpired с интервалом Config.CleanupInterval, пока работает Run.
+type ExpiringStore interface {
+	StateStore
+	DeleteExpired() error
+}
+
+// MemoryStore хранит состояния в памяти процесса. Состояния теряются при перезапуске.
+type MemoryStore struct {
+	cache *gocache.Cache
+}
+
+// NewMemoryStore создает хранилище в памяти, истекшие записи удаляются раз в cleanupInterval
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	return &MemoryStore{
+		cache: gocache.New(gocache.NoExpiration, cleanupInterval),
+	}
+}
+
+func (s *MemoryStore) Get(userID int64) (*StateEntry, error) {
+	value, ok := s.cache.Get(strconv.FormatInt(userID, 10))
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+
+	entry, ok := value.(*StateEntry)
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return entry.clone(), nil
+}
+
+func (s *MemoryStore) Set(userID int64, entry *StateEntry, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = gocache.NoExpiration
+	}
+	s.cache.Set(strconv.FormatInt(userID, 10), entry.clone(), ttl)
+	return nil
+}
+
+func (s *MemoryStore) Delete(userID int64) error {
+	s.cache.Delete(strconv.FormatInt(userID, 10))
+	return nil
+}
+
+// expiresAt возвращает момент истечения записи, сохраненной сейчас на ttl.
+// Нулевое время означает бессрочное хранение.
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
@@ -0,0 +1,133 @@
+package tg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileEntry запись о состоянии пользователя в файле
+type fileEntry struct {
+	StateEntry
+	ExpiresAt time.Time `json:"expiresAt,omitempty"` // Нулевое время - бессрочно
+}
+
+func (e fileEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// FileStore хранит состояния в JSON файле. Состояния переживают перезапуск,
+// но файл нельзя разделять между несколькими экземплярами бота.
+// Файл перезаписывается целиком при каждом изменении, поэтому хранилище
+// подходит для небольшого числа пользователей.
+type FileStore struct {
+	mu      sync.RWMutex
+	path    string
+	entries map[int64]fileEntry
+}
+
+// NewFileStore открывает хранилище в файле path. Если файла нет, он будет создан при первой записи.
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, ErrStateFileEmpty
+	}
+
+	s := &FileStore{
+		path:    path,
+		entries: make(map[int64]fileEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(userID int64) (*StateEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[userID]
+	if !ok || entry.expired(time.Now()) {
+		return nil, ErrStateNotFound
+	}
+	return entry.StateEntry.clone(), nil
+}
+
+func (s *FileStore) Set(userID int64, entry *StateEntry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[userID] = fileEntry{StateEntry: *entry.clone(), ExpiresAt: expiresAt(ttl)}
+	return s.save()
+}
+
+func (s *FileStore) Delete(userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[userID]; !ok {
+		return nil
+	}
+	delete(s.entries, userID)
+	return s.save()
+}
+
+// DeleteExpired удаляет истекшие состояния
+func (s *FileStore) DeleteExpired() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	deleted := false
+	for userID, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, userID)
+			deleted = true
+		}
+	}
+	if !deleted {
+		return nil
+	}
+	return s.save()
+}
+
+// save атомарно перезаписывает файл: пишет во временный файл и переименовывает его.
+// Вызывается под блокировкой.
+func (s *FileStore) save() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode user states: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
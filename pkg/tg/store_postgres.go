@@ -0,0 +1,106 @@
+package tg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultStateTable таблица состояний пользователей по умолчанию
+const DefaultStateTable = "bot_states"
+
+// tableNameRegex допустимое имя таблицы, подставляется в запросы без экранирования
+var tableNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// PostgresStore хранит состояния в таблице PostgreSQL.
+// Состояния переживают перезапуск и общие для всех экземпляров бота.
+// Драйвер базы данных подключает вызывающий код, таблицу создают миграции приложения:
+//
+//	CREATE TABLE bot_states (
+//	    user_id BIGINT PRIMARY KEY,
+//	    state VARCHAR(255) NOT NULL,
+//	    data JSONB NOT NULL DEFAULT '{}',
+//	    expires_at TIMESTAMP WITH TIME ZONE
+//	);
+type PostgresStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresStore создает хранилище в таблице table. Пустое имя - DefaultStateTable.
+func NewPostgresStore(db *sql.DB, table string) (*PostgresStore, error) {
+	if table == "" {
+		table = DefaultStateTable
+	}
+	if !tableNameRegex.MatchString(table) {
+		return nil, NewValidationError(ErrInvalidStateTable, table)
+	}
+	return &PostgresStore{db: db, table: table}, nil
+}
+
+func (s *PostgresStore) Get(userID int64) (*StateEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT state, data FROM %s
+		WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())`, s.table)
+
+	var (
+		entry StateEntry
+		data  []byte
+	)
+	err := s.db.QueryRow(query, userID).Scan(&entry.State, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user state: %w", err)
+	}
+	if err := json.Unmarshal(data, &entry.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode user session data: %w", err)
+	}
+	return &entry, nil
+}
+
+func (s *PostgresStore) Set(userID int64, entry *StateEntry, ttl time.Duration) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (user_id, state, data, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET state = EXCLUDED.state, data = EXCLUDED.data, expires_at = EXCLUDED.expires_at`, s.table)
+
+	data := []byte("{}")
+	if len(entry.Data) > 0 {
+		var err error
+		if data, err = json.Marshal(entry.Data); err != nil {
+			return fmt.Errorf("failed to encode user session data: %w", err)
+		}
+	}
+
+	var expires sql.NullTime
+	if at := expiresAt(ttl); !at.IsZero() {
+		expires = sql.NullTime{Time: at, Valid: true}
+	}
+
+	if _, err := s.db.Exec(query, userID, entry.State, data, expires); err != nil {
+		return fmt.Errorf("failed to set user state: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Delete(userID int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, s.table)
+	if _, err := s.db.Exec(query, userID); err != nil {
+		return fmt.Errorf("failed to delete user state: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет истекшие состояния
+func (s *PostgresStore) DeleteExpired() error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE expires_at <= NOW()`, s.table)
+	if _, err := s.db.Exec(query); err != nil {
+		return fmt.Errorf("failed to delete expired user states: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package tg
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testStores хранилища, которые проверяются одинаковыми сценариями
+func testStores(t *testing.T) map[string]StateStore {
+	t.Helper()
+	file, err := NewFileStore(filepath.Join(t.TempDir(), "states.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	return map[string]StateStore{
+		"memory": NewMemoryStore(0),
+		"file":   file,
+	}
+}
+
+func TestStateStore(t *testing.T) {
+	entry := &StateEntry{State: "form_text", Data: map[string]json.RawMessage{"category": json.RawMessage(`"dorm"`)}}
+
+	tests := []struct {
+		name    string
+		run     func(s StateStore) error
+		want    *StateEntry
+		wantErr error
+	}{
+		{
+			name:    "missing",
+			run:     func(StateStore) error { return nil },
+			wantErr: ErrStateNotFound,
+		},
+		{
+			name: "set",
+			run:  func(s StateStore) error { return s.Set(42, entry, 0) },
+			want: entry,
+		},
+		{
+			name: "overwrite",
+			run: func(s StateStore) error {
+				if err := s.Set(42, entry, 0); err != nil {
+					return err
+				}
+				return s.Set(42, &StateEntry{State: "start"}, time.Hour)
+			},
+			want: &StateEntry{State: "start"},
+		},
+		{
+			name: "delete",
+			run: func(s StateStore) error {
+				if err := s.Set(42, entry, 0); err != nil {
+					return err
+				}
+				return s.Delete(42)
+			},
+			wantErr: ErrStateNotFound,
+		},
+		{
+			name:    "delete missing",
+			run:     func(s StateStore) error { return s.Delete(42) },
+			wantErr: ErrStateNotFound,
+		},
+		{
+			name: "expired",
+			run: func(s StateStore) error {
+				if err := s.Set(42, entry, time.Millisecond); err != nil {
+					return err
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			},
+			wantErr: ErrStateNotFound,
+		},
+		{
+			name: "other user",
+			run:  func(s StateStore) error { return s.Set(7, entry, 0) },
+			// Запись другого пользователя не видна
+			wantErr: ErrStateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		for storeName, store := range testStores(t) {
+			t.Run(tt.name+"/"+storeName, func(t *testing.T) {
+				if err := tt.run(store); err != nil {
+					t.Fatalf("setup failed: %v", err)
+				}
+				got, err := store.Get(42)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Get() = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestStateStoreCopies(t *testing.T) {
+	for storeName, store := range testStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			entry := &StateEntry{State: "start", Data: map[string]json.RawMessage{"a": json.RawMessage(`1`)}}
+			if err := store.Set(42, entry, 0); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			// Изменения исходной и полученной записи не попадают в хранилище
+			entry.Data["a"] = json.RawMessage(`2`)
+			got, _ := store.Get(42)
+			got.Data["b"] = json.RawMessage(`3`)
+
+			got, _ = store.Get(42)
+			if want := map[string]json.RawMessage{"a": json.RawMessage(`1`)}; !reflect.DeepEqual(got.Data, want) {
+				t.Errorf("stored data = %s, want %s", got.Data, want)
+			}
+		})
+	}
+}
+
+func TestFileStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "states.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	if err := store.Set(42, &StateEntry{State: "form_text"}, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set(7, &StateEntry{State: "start"}, time.Millisecond); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := store.DeleteExpired(); err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+
+	// Новый экземпляр читает состояния из файла
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if got, err := reopened.Get(42); err != nil || got.State != "form_text" {
+		t.Errorf("Get(42) = %+v, %v", got, err)
+	}
+	if len(reopened.entries) != 1 {
+		t.Errorf("expired entries kept in file: %v", reopened.entries)
+	}
+
+	// Временные файлы удаляются после записи
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if len(files) != 0 {
+		t.Errorf("temp files left: %v", files)
+	}
+}
+
+func TestNewFileStore(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+		is      error
+	}{
+		{name: "empty path", path: "", wantErr: true, is: ErrStateFileEmpty},
+		{name: "missing file", path: filepath.Join(dir, "missing.json")},
+		{name: "empty file", path: write("empty.json", "")},
+		{name: "valid file", path: write("valid.json", `{"42":{"state":"start"}}`)},
+		{name: "broken file", path: write("broken.json", `{"42":`), wantErr: true},
+		{name: "directory", path: dir, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFileStore(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFileStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("NewFileStore() error = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestNewPostgresStore(t *testing.T) {
+	tests := []struct {
+		table   string
+		want    string
+		wantErr bool
+	}{
+		{table: "", want: DefaultStateTable},
+		{table: "tg_states", want: "tg_states"},
+		{table: "_states2", want: "_states2"},
+		{table: "2states", wantErr: true},
+		{table: "states; DROP TABLE users", wantErr: true},
+		{table: "public.states", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			store, err := NewPostgresStore(nil, tt.table)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStateTable) {
+					t.Errorf("NewPostgresStore(%q) error = %v, want %v", tt.table, err, ErrInvalidStateTable)
+				}
+				return
+			}
+			if err != nil || store.table != tt.want {
+				t.Errorf("NewPostgresStore(%q) = %v, %v", tt.table, store, err)
+			}
+		})
+	}
+}
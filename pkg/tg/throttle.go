@@ -0,0 +1,112 @@
+package tg
+
+import (
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
+)
+
+// throttleIdle время, после которого лимитер неактивного пользователя удаляется
+const throttleIdle = 10 * time.Minute
+
+// ThrottleConfig ограничение частоты обновлений одного пользователя для всех состояний.
+// Сокращение для Throttle(Limit, Burst) первым в Config.Middlewares.
+type ThrottleConfig struct {
+	Limit rate.Limit // Обновлений в секунду. 0 - без ограничения
+	Burst int        // Запас обновлений сверх Limit
+}
+
+// Throttle ограничивает частоту обработки обновлений одного пользователя:
+// limit обновлений в секунду с запасом burst. Подключается глобально в Config.Middlewares
+// или в State.Middlewares отдельного состояния. Одно обновление расходует один токен,
+// сколько бы обработчиков и AtEntranceFunc с этим middleware оно ни вызвало.
+// Лишние обновления пропускаются, на нажатия кнопок пользователь получает уведомление.
+func Throttle(limit rate.Limit, burst int) Middleware {
+	t := newThrottler(ThrottleConfig{Limit: limit, Burst: burst})
+	return func(next HandlerFunc) HandlerFunc {
+		return func(b *Bot, u tgbotapi.Update) error {
+			user := UpdateUser(u)
+			if t == nil || user == nil {
+				return next(b, u)
+			}
+
+			allowed, charged := t.allow(user.ID, u.UpdateID)
+			if allowed {
+				return next(b, u)
+			}
+			// Об отброшенном обновлении сообщаем один раз, а не в каждом обработчике
+			if charged {
+				if u.CallbackQuery != nil {
+					b.AnswerCallback(u.CallbackQuery.ID, "Слишком часто, подождите немного")
+				}
+				b.logger.Debug().Int64("user_id", user.ID).Int("update_id", u.UpdateID).Msg("update throttled")
+			}
+			return nil
+		}
+	}
+}
+
+// throttler лимитеры обновлений по пользователям
+type throttler struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	visitors  map[int64]*visitor
+	lastPrune time.Time
+}
+
+// visitor лимитер пользователя и решение по его последнему обновлению.
+// Обновления одного пользователя обрабатываются по очереди, поэтому повторный вызов
+// с тем же ID обновления - это следующий обработчик того же обновления.
+type visitor struct {
+	limiter     *rate.Limiter
+	lastSeen    time.Time
+	lastUpdate  int  // ID последнего учтенного обновления
+	lastAllowed bool // Решение по последнему учтенному обновлению
+}
+
+// newThrottler создает throttler или возвращает nil, если ограничение не задано
+func newThrottler(config ThrottleConfig) *throttler {
+	if config.Limit <= 0 {
+		return nil
+	}
+	return &throttler{
+		limit:     config.Limit,
+		burst:     config.Burst,
+		visitors:  make(map[int64]*visitor),
+		lastPrune: time.Now(),
+	}
+}
+
+// allow проверяет, можно ли обработать обновление updateID пользователя userID.
+// charged равен false, если решение по этому обновлению уже принято и токен не расходуется.
+func (t *throttler) allow(userID int64, updateID int) (allowed, charged bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.lastPrune) > time.Minute {
+		for id, v := range t.visitors {
+			if now.Sub(v.lastSeen) > throttleIdle {
+				delete(t.visitors, id)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	v, ok := t.visitors[userID]
+	if ok && v.lastUpdate == updateID {
+		return v.lastAllowed, false
+	}
+	if !ok {
+		v = &visitor{limiter: rate.NewLimiter(t.limit, t.burst)}
+		t.visitors[userID] = v
+	}
+	v.lastSeen = now
+	v.lastUpdate = updateID
+	v.lastAllowed = v.limiter.Allow()
+	return v.lastAllowed, true
+}
@@ -0,0 +1,151 @@
+package tg
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestThrottleOncePerUpdate(t *testing.T) {
+	var handled, entered int
+	states := map[string]State{
+		"start": {
+			Global:  true,
+			Context: true,
+			MessageHandlers: map[string]Handler{
+				"/form": {Handle: func(b *Bot, u tgbotapi.Update) error {
+					handled++
+					b.SetUserState(u.Message.From.ID, "form", false, &u)
+					return nil
+				}},
+			},
+			Transitions: []string{"form"},
+		},
+		"form": {
+			Context: true,
+			AtEntranceFunc: &Handler{Handle: func(*Bot, tgbotapi.Update) error {
+				entered++
+				return nil
+			}},
+		},
+	}
+	b := newTestBot(t, Config{States: states, Throttle: ThrottleConfig{Limit: 0.001, Burst: 2}})
+
+	// Переход с AtEntranceFunc расходует один токен: оба обновления проходят полностью
+	b.processUpdate(textUpdate(1, 42, "/form"))
+	b.processUpdate(textUpdate(2, 42, "/form"))
+	if handled != 2 || entered != 2 {
+		t.Fatalf("handled %d, entered %d, want 2 and 2", handled, entered)
+	}
+
+	// Запас исчерпан, третье обновление пропускается целиком
+	b.processUpdate(textUpdate(3, 42, "/form"))
+	if handled != 2 || entered != 2 {
+		t.Errorf("throttled update was handled: handled %d, entered %d", handled, entered)
+	}
+
+	// Лимит считается по пользователям
+	b.processUpdate(textUpdate(4, 43, "/form"))
+	if handled != 3 || entered != 3 {
+		t.Errorf("other user was throttled: handled %d, entered %d", handled, entered)
+	}
+}
+
+func TestThrottlerAllow(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ThrottleConfig
+		allowed int
+	}{
+		{name: "burst", config: ThrottleConfig{Limit: 0.001, Burst: 3}, allowed: 3},
+		{name: "single", config: ThrottleConfig{Limit: 0.001, Burst: 1}, allowed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := newThrottler(tt.config)
+			allowed := 0
+			for i := 1; i <= 10; i++ {
+				if ok, charged := th.allow(42, i); ok {
+					allowed++
+				} else if !charged {
+					t.Errorf("update %d was not charged", i)
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d of 10, want %d", allowed, tt.allowed)
+			}
+		})
+	}
+
+	if newThrottler(ThrottleConfig{}) != nil {
+		t.Error("zero limit must disable throttling")
+	}
+}
+
+func TestThrottlerSameUpdate(t *testing.T) {
+	th := newThrottler(ThrottleConfig{Limit: 0.001, Burst: 1})
+
+	tests := []struct {
+		name        string
+		userID      int64
+		updateID    int
+		wantAllowed bool
+		wantCharged bool
+	}{
+		{name: "first update", userID: 42, updateID: 1, wantAllowed: true, wantCharged: true},
+		{name: "same update again", userID: 42, updateID: 1, wantAllowed: true, wantCharged: false},
+		{name: "next update", userID: 42, updateID: 2, wantAllowed: false, wantCharged: true},
+		{name: "throttled update again", userID: 42, updateID: 2, wantAllowed: false, wantCharged: false},
+		{name: "same id other user", userID: 43, updateID: 2, wantAllowed: true, wantCharged: true},
+	}
+
+	// Шаги выполняются по порядку на одном throttler
+	for _, tt := range tests {
+		allowed, charged := th.allow(tt.userID, tt.updateID)
+		if allowed != tt.wantAllowed || charged != tt.wantCharged {
+			t.Errorf("%s: allow() = %v, %v, want %v, %v", tt.name, allowed, charged, tt.wantAllowed, tt.wantCharged)
+		}
+	}
+}
+
+func TestThrottleInState(t *testing.T) {
+	var menu, form int
+	states := map[string]State{
+		"start": {
+			Global: true,
+			MessageHandlers: map[string]Handler{
+				"/menu": {Handle: func(*Bot, tgbotapi.Update) error {
+					menu++
+					return nil
+				}},
+			},
+		},
+		"form": {
+			Context:     true,
+			Middlewares: []Middleware{Throttle(0.001, 1)},
+			CatchAllFunc: &Handler{Handle: func(*Bot, tgbotapi.Update) error {
+				form++
+				return nil
+			}},
+		},
+	}
+	b := newTestBot(t, Config{States: states})
+	api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+	b.SetUserState(42, "form", false, nil)
+
+	// Ограничение действует только на обработчики состояния form
+	for i := 1; i <= 3; i++ {
+		b.processUpdate(textUpdate(i, 42, "answer"))
+		b.processUpdate(textUpdate(10+i, 42, "/menu"))
+	}
+	if form != 1 || menu != 3 {
+		t.Errorf("form handled %d, menu handled %d; want 1 and 3", form, menu)
+	}
+
+	// На лишнее нажатие кнопки пользователь получает одно уведомление
+	b.processUpdate(callbackUpdate(20, 42, "skip"))
+	if calls := api.methods(); len(calls) != 1 || calls[0] != "answerCallbackQuery" {
+		t.Errorf("calls = %v, want one answerCallbackQuery", calls)
+	}
+}
@@ -0,0 +1,175 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	pollTimeout    = 30              // Время ожидания обновлений в getUpdates, секунды
+	pollRetryDelay = 3 * time.Second // Пауза перед повтором getUpdates после ошибки
+)
+
+// AllowedUpdates типы обновлений, которые бот запрашивает у Telegram.
+// Без явного указания Telegram не присылает chat_member.
+var AllowedUpdates = []string{
+	"message",
+	"edited_message",
+	"callback_query",
+	"inline_query",
+	"chosen_inline_result",
+	"my_chat_member",
+	"chat_member",
+	"chat_join_request",
+}
+
+// WebAppData данные, отправленные Mini App через Telegram.WebApp.sendData
+type WebAppData struct {
+	Data       string `json:"data"`        // Данные от Mini App
+	ButtonText string `json:"button_text"` // Текст кнопки, которой открыт Mini App
+}
+
+// updateExtra поля обновления, которые не разбирает tgbotapi
+type updateExtra struct {
+	Message *struct {
+		WebAppData *WebAppData `json:"web_app_data"`
+	} `json:"message"`
+}
+
+// decodeUpdate разбирает обновление и сохраняет поля, которых нет в tgbotapi.Update
+func (app *Bot) decodeUpdate(data []byte) (tgbotapi.Update, error) {
+	var update tgbotapi.Update
+	if err := json.Unmarshal(data, &update); err != nil {
+		return update, fmt.Errorf("failed to decode update: %w", err)
+	}
+
+	var extra updateExtra
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return update, fmt.Errorf("failed to decode update: %w", err)
+	}
+	if extra.Message != nil && extra.Message.WebAppData != nil {
+		app.webAppData.Store(update.UpdateID, extra.Message.WebAppData)
+	}
+	return update, nil
+}
+
+// WebAppData возвращает данные Mini App из сообщения web_app_data или nil
+func (app *Bot) WebAppData(u tgbotapi.Update) *WebAppData {
+	if data, ok := app.webAppData.Load(u.UpdateID); ok {
+		return data.(*WebAppData)
+	}
+	return nil
+}
+
+// forgetUpdate удаляет сохраненные поля обработанного или отброшенного обновления
+func (app *Bot) forgetUpdate(u tgbotapi.Update) {
+	app.webAppData.Delete(u.UpdateID)
+}
+
+// receive передает полученное обновление в цикл Run.
+// Возвращает false, если бот остановлен или ctx отменен.
+func (app *Bot) receive(ctx context.Context, update tgbotapi.Update) bool {
+	select {
+	case app.updates <- update:
+		return true
+	case <-app.stop:
+	case <-ctx.Done():
+	}
+	app.forgetUpdate(update)
+	return false
+}
+
+// poll получает обновления через getUpdates до остановки бота
+func (app *Bot) poll(ctx context.Context) {
+	offset := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-app.stop:
+			return
+		default:
+		}
+
+		params := make(tgbotapi.Params)
+		params.AddNonZero("offset", offset)
+		params.AddNonZero("timeout", pollTimeout)
+		if err := params.AddInterface("allowed_updates", AllowedUpdates); err != nil {
+			app.logger.Error().Err(err).Msg("failed to encode allowed updates")
+			return
+		}
+
+		resp, err := app.BotAPI.MakeRequest("getUpdates", params)
+		if err != nil {
+			app.logger.Error().Err(err).Msg("failed to get updates, retrying")
+			select {
+			case <-time.After(pollRetryDelay):
+				continue
+			case <-ctx.Done():
+				return
+			case <-app.stop:
+				return
+			}
+		}
+
+		var raws []json.RawMessage
+		if err := json.Unmarshal(resp.Result, &raws); err != nil {
+			app.logger.Error().Err(err).Msg("failed to decode updates")
+			continue
+		}
+
+		for _, raw := range raws {
+			update, err := app.decodeUpdate(raw)
+			if err != nil {
+				app.logger.Error().Err(err).Msg("failed to decode update")
+				continue
+			}
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			if !app.receive(ctx, update) {
+				return
+			}
+		}
+	}
+}
+
+// UpdateUser возвращает пользователя, от которого пришло обновление, или nil.
+// В отличие от tgbotapi.Update.SentFrom учитывает изменения участников и заявки на вступление.
+func UpdateUser(u tgbotapi.Update) *tgbotapi.User {
+	switch {
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.From
+	case u.ChatMember != nil:
+		return &u.ChatMember.From
+	case u.ChatJoinRequest != nil:
+		return &u.ChatJoinRequest.From
+	default:
+		return u.SentFrom()
+	}
+}
+
+// UpdateChat возвращает чат обновления или nil.
+// В отличие от tgbotapi.Update.FromChat учитывает изменения участников, заявки на вступление
+// и не паникует на callback из inline сообщений.
+func UpdateChat(u tgbotapi.Update) *tgbotapi.Chat {
+	switch {
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return &u.ChatMember.Chat
+	case u.ChatJoinRequest != nil:
+		return &u.ChatJoinRequest.Chat
+	case u.CallbackQuery != nil:
+		if u.CallbackQuery.Message == nil {
+			return nil
+		}
+		return u.CallbackQuery.Message.Chat
+	default:
+		return u.FromChat()
+	}
+}
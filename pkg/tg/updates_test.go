@@ -0,0 +1,44 @@
+package tg
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestUpdateUserAndChat(t *testing.T) {
+	user := tgbotapi.User{ID: 42, FirstName: "Ivan"}
+	chat := tgbotapi.Chat{ID: -100200, Type: "supergroup"}
+
+	tests := []struct {
+		name     string
+		update   tgbotapi.Update
+		wantUser int64 // 0 - пользователя нет
+		wantChat int64 // 0 - чата нет
+	}{
+		{name: "message", update: textUpdate(1, 42, "hi"), wantUser: 42, wantChat: 42},
+		{name: "callback", update: callbackUpdate(1, 42, "take"), wantUser: 42, wantChat: 42},
+		{name: "inline callback", update: tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: &user, InlineMessageID: "m"}}, wantUser: 42},
+		{name: "inline query", update: tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &user}}, wantUser: 42},
+		{name: "my chat member", update: tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{From: user, Chat: chat}}, wantUser: 42, wantChat: -100200},
+		{name: "chat member", update: tgbotapi.Update{ChatMember: &tgbotapi.ChatMemberUpdated{From: user, Chat: chat}}, wantUser: 42, wantChat: -100200},
+		{name: "join request", update: tgbotapi.Update{ChatJoinRequest: &tgbotapi.ChatJoinRequest{From: user, Chat: chat}}, wantUser: 42, wantChat: -100200},
+		{name: "channel post", update: tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: &chat}}, wantChat: -100200},
+		{name: "empty", update: tgbotapi.Update{UpdateID: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser, gotChat int64
+			if u := UpdateUser(tt.update); u != nil {
+				gotUser = u.ID
+			}
+			if c := UpdateChat(tt.update); c != nil {
+				gotChat = c.ID
+			}
+			if gotUser != tt.wantUser || gotChat != tt.wantChat {
+				t.Errorf("user %d, chat %d; want %d, %d", gotUser, gotChat, tt.wantUser, tt.wantChat)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package tg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// validateStates проверяет граф состояний и возвращает все найденные ошибки одной
+// ValidationError с ErrInvalidStateGraph:
+//   - Transitions ссылаются только на объявленные состояния;
+//   - у глобальных состояний нет AtEntranceFunc;
+//   - ключи MessageHandlers и CallbackHandlers не повторяются в разных глобальных состояниях,
+//     иначе обработчик выбирается в зависимости от порядка состояний;
+//   - в каждое локальное состояние можно попасть по Transitions из глобальных.
+//     Проверка выполняется, только если хотя бы одно состояние объявляет Transitions.
+func validateStates(states map[string]State) error {
+	names := sortedStateNames(states)
+
+	var problems []error
+	declared := false
+	for _, name := range names {
+		state := states[name]
+		for _, to := range state.Transitions {
+			declared = true
+			if _, ok := states[to]; !ok {
+				problems = append(problems, NewValidationError(ErrUnknownTransition, fmt.Sprintf("%s -> %s", name, to)))
+			}
+		}
+		if state.Global && state.AtEntranceFunc != nil {
+			problems = append(problems, NewValidationError(ErrGlobalEntrance, name))
+		}
+	}
+
+	problems = append(problems, duplicateGlobalHandlers(states, names)...)
+
+	if declared {
+		reachable := reachableStates(states, names)
+		for _, name := range names {
+			if !reachable[name] {
+				problems = append(problems, NewValidationError(ErrUnreachableState, name))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return NewValidationError(ErrInvalidStateGraph, errors.Join(problems...))
+}
+
+// duplicateGlobalHandlers ищет ключи обработчиков, объявленные в нескольких глобальных состояниях
+func duplicateGlobalHandlers(states map[string]State, names []string) []error {
+	var problems []error
+	messages := make(map[string]string)
+	callbacks := make(map[string]string)
+
+	check := func(owners map[string]string, kind, key, name string) {
+		if owner, ok := owners[key]; ok {
+			problems = append(problems, NewValidationError(ErrDuplicateGlobalHandler,
+				fmt.Sprintf("%s %q in %s and %s", kind, key, owner, name)))
+			return
+		}
+		owners[key] = name
+	}
+
+	for _, name := range names {
+		state := states[name]
+		if !state.Global {
+			continue
+		}
+		for _, key := range sortedKeys(state.MessageHandlers) {
+			check(messages, "message", key, name)
+		}
+		for _, key := range sortedKeys(state.CallbackHandlers) {
+			check(callbacks, "callback", key, name)
+		}
+	}
+	return problems
+}
+
+// reachableStates возвращает состояния, в которые можно попасть по Transitions из глобальных
+func reachableStates(states map[string]State, names []string) map[string]bool {
+	reachable := make(map[string]bool)
+	var queue []string
+	for _, name := range names {
+		if states[name].Global {
+			reachable[name] = true
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, to := range states[name].Transitions {
+			if _, ok := states[to]; ok && !reachable[to] {
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reachable
+}
+
+// sortedStateNames возвращает имена состояний по алфавиту
+func sortedStateNames(states map[string]State) []string {
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys возвращает ключи обработчиков по алфавиту
+func sortedKeys(handlers map[string]Handler) []string {
+	keys := make([]string, 0, len(handlers))
+	for key := range handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
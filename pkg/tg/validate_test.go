@@ -0,0 +1,135 @@
+package tg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// validationProblems возвращает тексты ошибок, собранных validateStates
+func validationProblems(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var graphErr *ValidationError
+	if !errors.As(err, &graphErr) || graphErr.Err != ErrInvalidStateGraph {
+		t.Fatalf("error %v is not a state graph error", err)
+	}
+	joined, ok := graphErr.Value.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("problems are not joined: %v", graphErr.Value)
+	}
+	var problems []string
+	for _, problem := range joined.Unwrap() {
+		problems = append(problems, problem.Error())
+	}
+	return problems
+}
+
+func TestValidateStates(t *testing.T) {
+	entrance := &Handler{}
+
+	tests := []struct {
+		name   string
+		states map[string]State
+		want   []string
+	}{
+		{
+			name:   "empty",
+			states: map[string]State{},
+		},
+		{
+			name: "without transitions",
+			states: map[string]State{
+				"start": {Global: true},
+				"menu":  {AtEntranceFunc: entrance},
+			},
+		},
+		{
+			name: "valid graph",
+			states: map[string]State{
+				"start":     {Global: true, Transitions: []string{"menu"}},
+				"menu":      {AtEntranceFunc: entrance, Transitions: []string{"form_name", "menu"}},
+				"form_name": {Transitions: []string{"menu"}},
+			},
+		},
+		{
+			name: "unknown transition",
+			states: map[string]State{
+				"start": {Global: true, Transitions: []string{"menu", "form"}},
+				"menu":  {},
+			},
+			want: []string{"transition to undefined state: start -> form"},
+		},
+		{
+			name: "global entrance",
+			states: map[string]State{
+				"start": {Global: true, AtEntranceFunc: entrance},
+			},
+			want: []string{"global state has entrance function: start"},
+		},
+		{
+			name: "duplicate global handlers",
+			states: map[string]State{
+				"admin": {Global: true, MessageHandlers: map[string]Handler{"/help": {}}, CallbackHandlers: map[string]Handler{"take": {}}},
+				"start": {Global: true, MessageHandlers: map[string]Handler{"/help": {}, "/start": {}}, CallbackHandlers: map[string]Handler{"take": {}}},
+				"menu":  {MessageHandlers: map[string]Handler{"/help": {}}},
+			},
+			want: []string{
+				`handler key is declared in several global states: message "/help" in admin and start`,
+				`handler key is declared in several global states: callback "take" in admin and start`,
+			},
+		},
+		{
+			name: "unreachable state",
+			states: map[string]State{
+				"start":   {Global: true, Transitions: []string{"menu"}},
+				"menu":    {Transitions: []string{"start"}},
+				"orphan":  {Transitions: []string{"menu"}},
+				"island":  {Transitions: []string{"island"}},
+				"handled": {},
+			},
+			want: []string{
+				"state is unreachable: handled",
+				"state is unreachable: island",
+				"state is unreachable: orphan",
+			},
+		},
+		{
+			name: "all problems together",
+			states: map[string]State{
+				"start": {Global: true, AtEntranceFunc: entrance, Transitions: []string{"missing"}},
+				"menu":  {},
+			},
+			want: []string{
+				"transition to undefined state: start -> missing",
+				"global state has entrance function: start",
+				"state is unreachable: menu",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStates(tt.states)
+			if got := validationProblems(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStatesErrorsIs(t *testing.T) {
+	err := validateStates(map[string]State{
+		"start": {Global: true, Transitions: []string{"missing"}},
+	})
+	for _, target := range []error{ErrInvalidStateGraph, ErrUnknownTransition} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(%v) = false", target)
+		}
+	}
+	if errors.Is(err, ErrUnreachableState) {
+		t.Error("unexpected unreachable state error")
+	}
+}
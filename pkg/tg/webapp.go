@@ -0,0 +1,85 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Decode разбирает данные Mini App как JSON в v
+func (d *WebAppData) Decode(v any) error {
+	if err := json.Unmarshal([]byte(d.Data), v); err != nil {
+		return fmt.Errorf("failed to decode web app data: %w", err)
+	}
+	return nil
+}
+
+// DecodeWebAppData разбирает данные Mini App из обновления как JSON в значение типа T.
+// Возвращает ErrNoWebAppData, если в обновлении нет web_app_data.
+func DecodeWebAppData[T any](b *Bot, u tgbotapi.Update) (T, error) {
+	var value T
+	data := b.WebAppData(u)
+	if data == nil {
+		return value, ErrNoWebAppData
+	}
+	err := data.Decode(&value)
+	return value, err
+}
+
+// WebAppInfo адрес Mini App для кнопки клавиатуры
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// WebAppKeyboardButton кнопка обычной клавиатуры, открывающая Mini App.
+// Только Mini App, открытые такой кнопкой, могут отправить данные через Telegram.WebApp.sendData.
+type WebAppKeyboardButton struct {
+	Text   string     `json:"text"`
+	WebApp WebAppInfo `json:"web_app"`
+}
+
+// WebAppReplyKeyboard обычная клавиатура с кнопками Mini App.
+// Используется как ReplyMarkup вместо tgbotapi.ReplyKeyboardMarkup, в котором нет web_app.
+type WebAppReplyKeyboard struct {
+	Keyboard        [][]any `json:"keyboard"`
+	ResizeKeyboard  bool    `json:"resize_keyboard"`
+	OneTimeKeyboard bool    `json:"one_time_keyboard"`
+}
+
+// NewWebAppReplyKeyboard создает клавиатуру из строк кнопок.
+// Кнопки - tgbotapi.KeyboardButton или WebAppKeyboardButton.
+func NewWebAppReplyKeyboard(rows ...[]any) WebAppReplyKeyboard {
+	return WebAppReplyKeyboard{
+		Keyboard:       rows,
+		ResizeKeyboard: true,
+	}
+}
+
+// SentWebAppMessage результат answerWebAppQuery
+type SentWebAppMessage struct {
+	InlineMessageID string `json:"inline_message_id"`
+}
+
+// AnswerWebAppQuery отправляет сообщение от имени пользователя в чат, из которого
+// открыт Mini App. queryID - query_id из initData Mini App, result - результат inline запроса,
+// например tgbotapi.NewInlineQueryResultArticle.
+func (app *Bot) AnswerWebAppQuery(ctx context.Context, queryID string, result any) (*SentWebAppMessage, error) {
+	params := make(tgbotapi.Params)
+	params["web_app_query_id"] = queryID
+	if err := params.AddInterface("result", result); err != nil {
+		return nil, fmt.Errorf("failed to encode web app query result: %w", err)
+	}
+
+	resp, err := app.makeRequest(ctx, "answerWebAppQuery", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to answer web app query: %w", err)
+	}
+
+	var sent SentWebAppMessage
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return nil, fmt.Errorf("failed to decode answerWebAppQuery result: %w", err)
+	}
+	return &sent, nil
+}
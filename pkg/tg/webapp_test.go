@@ -0,0 +1,139 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webAppUpdate сообщение web_app_data с данными data в JSON, как его присылает Telegram
+func webAppUpdate(data string) string {
+	payload, _ := json.Marshal(WebAppData{Data: data, ButtonText: "Заявка"})
+	return `{"update_id":9,"message":{"message_id":1,"from":{"id":42},"chat":{"id":42,"type":"private"},"web_app_data":` + string(payload) + `}}`
+}
+
+func TestDecodeWebAppData(t *testing.T) {
+	type form struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    form
+		wantErr bool
+		is      error
+	}{
+		{name: "form", raw: webAppUpdate(`{"name":"Ivan"}`), want: form{Name: "Ivan"}},
+		{name: "extra fields", raw: webAppUpdate(`{"name":"Ivan","age":20}`), want: form{Name: "Ivan"}},
+		{name: "invalid json", raw: webAppUpdate(`name=Ivan`), wantErr: true},
+		{name: "text message", raw: `{"update_id":9,"message":{"message_id":1,"chat":{"id":42,"type":"private"},"text":"hi"}}`, wantErr: true, is: ErrNoWebAppData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			update, err := b.decodeUpdate([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("decodeUpdate failed: %v", err)
+			}
+
+			got, err := DecodeWebAppData[form](b, update)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeWebAppData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("DecodeWebAppData() error = %v, want %v", err, tt.is)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeWebAppData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebAppDataForgotten(t *testing.T) {
+	var data *WebAppData
+	b := newTestBot(t, Config{States: map[string]State{
+		"start": {
+			Global: true,
+			WebAppDataHandler: &Handler{Handle: func(b *Bot, u tgbotapi.Update) error {
+				data = b.WebAppData(u)
+				return nil
+			}},
+		},
+	}})
+
+	update, err := b.decodeUpdate([]byte(webAppUpdate(`{"name":"Ivan"}`)))
+	if err != nil {
+		t.Fatalf("decodeUpdate failed: %v", err)
+	}
+	b.processUpdate(update)
+
+	if data == nil || data.Data != `{"name":"Ivan"}` || data.ButtonText != "Заявка" {
+		t.Errorf("handler got %+v", data)
+	}
+	// Данные удаляются после обработки и не копятся в памяти
+	if got := b.WebAppData(update); got != nil {
+		t.Errorf("web app data kept after handling: %+v", got)
+	}
+}
+
+func TestNewWebAppReplyKeyboard(t *testing.T) {
+	keyboard := NewWebAppReplyKeyboard(
+		[]any{WebAppKeyboardButton{Text: "Заявка", WebApp: WebAppInfo{URL: "https://example.com/app"}}},
+		[]any{tgbotapi.NewKeyboardButton("Мои заявки")},
+	)
+
+	got, err := json.Marshal(keyboard)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"keyboard":[[{"text":"Заявка","web_app":{"url":"https://example.com/app"}}],[{"text":"Мои заявки"}]],"resize_keyboard":true,"one_time_keyboard":false}`
+	if string(got) != want {
+		t.Errorf("keyboard = %s, want %s", got, want)
+	}
+}
+
+func TestAnswerWebAppQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		response tgbotapi.APIResponse
+		want     string
+		wantErr  bool
+	}{
+		{name: "sent", response: okResponse(map[string]string{"inline_message_id": "msg-1"}), want: "msg-1"},
+		{name: "query expired", response: errorResponse(400, "Bad Request: query is too old and response timeout expired or query ID is invalid", nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{States: map[string]State{}})
+			api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return tt.response })
+
+			result := tgbotapi.NewInlineQueryResultArticle("1", "Заявка", "Заявка отправлена")
+			sent, err := b.AnswerWebAppQuery(context.Background(), "query-1", result)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AnswerWebAppQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && sent.InlineMessageID != tt.want {
+				t.Errorf("InlineMessageID = %q, want %q", sent.InlineMessageID, tt.want)
+			}
+
+			if len(api.calls) == 0 || api.calls[0].method != "answerWebAppQuery" {
+				t.Fatalf("calls = %+v", api.calls)
+			}
+			params := api.calls[0].params
+			if params.Get("web_app_query_id") != "query-1" {
+				t.Errorf("web_app_query_id = %q", params.Get("web_app_query_id"))
+			}
+			var sentResult tgbotapi.InlineQueryResultArticle
+			if err := json.Unmarshal([]byte(params.Get("result")), &sentResult); err != nil || sentResult.ID != "1" || sentResult.Type != "article" {
+				t.Errorf("result = %s, %v", params.Get("result"), err)
+			}
+		})
+	}
+}
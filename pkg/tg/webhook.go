@@ -0,0 +1,120 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UpdateMode способ получения обновлений от Telegram
+type UpdateMode string
+
+const (
+	UpdateModePolling UpdateMode = "polling" // Long polling через getUpdates
+	UpdateModeWebhook UpdateMode = "webhook" // Telegram отправляет обновления на WebhookConfig.URL
+)
+
+// maxWebhookBody максимальный размер тела запроса webhook
+const maxWebhookBody = 10 << 20
+
+// secretTokenHeader заголовок, в котором Telegram передает secret_token webhook'а
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// secretTokenPattern допустимый формат secret_token: 1-256 символов A-Z, a-z, 0-9, _ и -
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// WebhookConfig настройки получения обновлений через webhook
+type WebhookConfig struct {
+	URL                string // Публичный HTTPS адрес, на который Telegram будет отправлять обновления
+	SecretToken        string // Секрет, который Telegram передает в заголовке X-Telegram-Bot-Api-Secret-Token
+	MaxConnections     int    // Максимум одновременных соединений от Telegram. 0 - значение по умолчанию (40)
+	DropPendingUpdates bool   // Если true, накопившиеся обновления удаляются при установке webhook
+}
+
+// validate проверяет настройки webhook
+func (c WebhookConfig) validate() error {
+	if c.URL == "" {
+		return ErrWebhookURLEmpty
+	}
+	if c.SecretToken != "" && !secretTokenPattern.MatchString(c.SecretToken) {
+		return NewValidationError(ErrInvalidWebhookSecret, len(c.SecretToken))
+	}
+	return nil
+}
+
+// setWebhook регистрирует webhook в Telegram
+func (app *Bot) setWebhook(ctx context.Context) error {
+	params := make(tgbotapi.Params)
+	params["url"] = app.webhook.URL
+	params.AddNonEmpty("secret_token", app.webhook.SecretToken)
+	params.AddNonZero("max_connections", app.webhook.MaxConnections)
+	params.AddBool("drop_pending_updates", app.webhook.DropPendingUpdates)
+	if err := params.AddInterface("allowed_updates", AllowedUpdates); err != nil {
+		return fmt.Errorf("не удалось установить webhook: %w", err)
+	}
+
+	if _, err := app.makeRequest(ctx, "setWebhook", params); err != nil {
+		return fmt.Errorf("не удалось установить webhook: %w", err)
+	}
+	app.logger.Info().Str("url", app.webhook.URL).Msg("Webhook установлен")
+	return nil
+}
+
+// deleteWebhook удаляет webhook в Telegram
+func (app *Bot) deleteWebhook(ctx context.Context) error {
+	if _, err := app.Request(ctx, tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("не удалось удалить webhook: %w", err)
+	}
+	return nil
+}
+
+// WebhookHandler возвращает обработчик HTTP запросов от Telegram для режима webhook.
+// Обработчик проверяет заголовок X-Telegram-Bot-Api-Secret-Token и передает обновление
+// в общую очередь обработки. Его можно смонтировать в любой маршрутизатор по пути из WebhookConfig.URL.
+func (app *Bot) WebhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.mode != UpdateModeWebhook {
+			http.Error(w, "webhook mode is disabled", http.StatusNotFound)
+			return
+		}
+
+		if app.webhook.SecretToken != "" {
+			token := r.Header.Get(secretTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(app.webhook.SecretToken)) != 1 {
+				app.logger.Warn().
+					Str("remote_addr", r.RemoteAddr).
+					Msg("Запрос webhook с неверным secret_token")
+				http.Error(w, "invalid secret token", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+		if err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+		update, err := app.decodeUpdate(body)
+		if err != nil {
+			app.logger.Error().Err(err).Msg("Не удалось разобрать обновление webhook")
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		if !app.receive(r.Context(), update) {
+			// Бот остановлен или запрос отменен, Telegram повторит доставку позже
+			http.Error(w, "bot is stopped", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
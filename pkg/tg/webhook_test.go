@@ -0,0 +1,143 @@
+package tg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  WebhookConfig
+		wantErr error
+	}{
+		{name: "url only", config: WebhookConfig{URL: "https://example.com/tg"}},
+		{name: "with secret", config: WebhookConfig{URL: "https://example.com/tg", SecretToken: "s3cret_Token-1"}},
+		{name: "empty url", config: WebhookConfig{SecretToken: "secret"}, wantErr: ErrWebhookURLEmpty},
+		{name: "secret with spaces", config: WebhookConfig{URL: "https://example.com/tg", SecretToken: "my secret"}, wantErr: ErrInvalidWebhookSecret},
+		{name: "long secret", config: WebhookConfig{URL: "https://example.com/tg", SecretToken: strings.Repeat("a", 257)}, wantErr: ErrInvalidWebhookSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	const update = `{"update_id":5,"message":{"message_id":1,"from":{"id":42,"first_name":"Ivan"},"chat":{"id":42,"type":"private"},"text":"/start"}}`
+
+	tests := []struct {
+		name     string
+		mode     UpdateMode
+		secret   string // Заголовок X-Telegram-Bot-Api-Secret-Token
+		method   string
+		body     string
+		stopped  bool
+		status   int
+		received bool
+	}{
+		{name: "update", mode: UpdateModeWebhook, secret: "secret", method: http.MethodPost, body: update, status: http.StatusOK, received: true},
+		{name: "polling mode", mode: UpdateModePolling, secret: "secret", method: http.MethodPost, body: update, status: http.StatusNotFound},
+		{name: "wrong secret", mode: UpdateModeWebhook, secret: "other", method: http.MethodPost, body: update, status: http.StatusUnauthorized},
+		{name: "missing secret", mode: UpdateModeWebhook, method: http.MethodPost, body: update, status: http.StatusUnauthorized},
+		{name: "get", mode: UpdateModeWebhook, secret: "secret", method: http.MethodGet, status: http.StatusMethodNotAllowed},
+		{name: "invalid json", mode: UpdateModeWebhook, secret: "secret", method: http.MethodPost, body: `{"update_id":`, status: http.StatusBadRequest},
+		{name: "stopped bot", mode: UpdateModeWebhook, secret: "secret", method: http.MethodPost, body: update, stopped: true, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot(t, Config{
+				States:  map[string]State{},
+				Mode:    tt.mode,
+				Webhook: WebhookConfig{URL: "https://example.com/tg", SecretToken: "secret"},
+			})
+			if tt.stopped {
+				// Бот остановлен, а очередь заполнена: обновление некуда передать
+				close(b.stop)
+				b.updates = make(chan tgbotapi.Update)
+			}
+
+			req := httptest.NewRequest(tt.method, "/tg", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(secretTokenHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			b.WebhookHandler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			select {
+			case u := <-b.updates:
+				if !tt.received || u.UpdateID != 5 || u.Message.Text != "/start" {
+					t.Errorf("received update %+v", u)
+				}
+			default:
+				if tt.received {
+					t.Error("update was not received")
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookWithoutSecret(t *testing.T) {
+	b := newTestBot(t, Config{
+		States:  map[string]State{},
+		Mode:    UpdateModeWebhook,
+		Webhook: WebhookConfig{URL: "https://example.com/tg"},
+	})
+	rec := httptest.NewRecorder()
+	b.WebhookHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tg", strings.NewReader(`{"update_id":1}`)))
+	if rec.Code != http.StatusOK || len(b.updates) != 1 {
+		t.Errorf("status = %d, queued %d", rec.Code, len(b.updates))
+	}
+}
+
+func TestSetWebhook(t *testing.T) {
+	b := newTestBot(t, Config{
+		States: map[string]State{},
+		Mode:   UpdateModeWebhook,
+		Webhook: WebhookConfig{
+			URL:                "https://example.com/tg",
+			SecretToken:        "secret",
+			MaxConnections:     10,
+			DropPendingUpdates: true,
+		},
+	})
+	api := withFakeAPI(b, func(apiCall) tgbotapi.APIResponse { return okResponse(true) })
+
+	if err := b.setWebhook(context.Background()); err != nil {
+		t.Fatalf("setWebhook failed: %v", err)
+	}
+	if len(api.calls) != 1 || api.calls[0].method != "setWebhook" {
+		t.Fatalf("calls = %+v", api.calls)
+	}
+
+	params := api.calls[0].params
+	want := map[string]string{
+		"url":                  "https://example.com/tg",
+		"secret_token":         "secret",
+		"max_connections":      "10",
+		"drop_pending_updates": "true",
+	}
+	for key, value := range want {
+		if got := params.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if allowed := params.Get("allowed_updates"); !strings.Contains(allowed, `"chat_member"`) {
+		t.Errorf("allowed_updates = %s", allowed)
+	}
+}